@@ -1,153 +1,100 @@
 // Package race provides functionality to call http requests simultaneously
-// and get the result from the fastest one
+// and get the result from the fastest one.
+//
+// This is the v1 API, kept for its existing importers as thin wrappers over
+// github.com/mostafa-asg/race/v2, which has the context-aware API with options and results
 package race
 
 import (
-	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	race "github.com/mostafa-asg/race/v2"
 )
 
 // Race between requests
+//
+// Deprecated: use the Race of github.com/mostafa-asg/race/v2
 type Race struct {
-	client *http.Client
+	race *race.Race
 }
 
 // Between gets a bunch of requests and makes http request simultaneously to all of them
 // the first answer will be returned
-func (race *Race) Between(reqs ...*http.Request) (*http.Response, error) {
-	ctx, cancel := createContext(race.client.Timeout)
-	defer cancel()
-
-	onComplete := make(chan *http.Response)
-	onError := make(chan error)
-
-	// run all the requests concurrently
-	for _, r := range reqs {
-		req := r.WithContext(ctx)
-		go race.makeRequest(onComplete, onError, req)
-	}
-
-	var errs []error
-	for {
-		select {
-		case res := <-onComplete:
-			return res, nil
-		case err := <-onError:
-			errs = append(errs, err)
-
-			// all requests failed
-			if len(errs) == len(reqs) {
-				allerrors := &multierror.Error{}
-				multierror.Append(allerrors, errs...)
-				return nil, allerrors
-			}
-		}
-	}
+func (r *Race) Between(reqs ...*http.Request) (*http.Response, error) {
+	res, err := r.race.Between(reqs...)
+	return res, v1Error(err)
 }
 
 // FirstThenStart starts the given requests and if the given timeout elapses or
 // error happens it starts the other requests concurently
-func (race *Race) FirstThenStart(first *http.Request, timeout time.Duration, reqs ...*http.Request) (*http.Response, error) {
-	// the porpuse of this context is to cancel all ongoing requests at the end
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// after this timeout all the other requests should be started
-	ctxFirstTimeout, cancelFirst := context.WithTimeout(context.Background(), timeout)
-	defer cancelFirst()
-
-	onComplete := make(chan *http.Response)
-	onError := make(chan error)
-
-	go race.makeRequest(onComplete, onError, first.WithContext(ctx))
-
-	var firstErr error
-FOR:
-	for {
-		select {
-		case res := <-onComplete:
-			return res, nil
-		case <-ctxFirstTimeout.Done():
-			break FOR
-		case firstErr = <-onError:
-			break FOR
-		}
-	}
-
-	// either timeout or an error happend
-	// start the other requests
-	for _, req := range reqs {
-		go race.makeRequest(onComplete, onError, req.WithContext(ctx))
-	}
-
-	var errs []error
-	for {
-		select {
-		case res := <-onComplete:
-			return res, nil
-		case err := <-onError:
-			errs = append(errs, err)
-
-			// all requests failed
-			if len(errs) == len(reqs) {
-				allerrors := &multierror.Error{}
-				if firstErr != nil {
-					multierror.Append(allerrors, firstErr)
-				}
-				multierror.Append(allerrors, errs...)
-				return nil, allerrors
-			}
-		}
+func (r *Race) FirstThenStart(first *http.Request, timeout time.Duration, reqs ...*http.Request) (*http.Response, error) {
+	// v2 limits the first request by the timeout when there is no other request
+	if len(reqs) == 0 {
+		return r.Between(first)
 	}
+	res, err := r.race.FirstThenStart(first, timeout, reqs...)
+	return res, v1Error(err)
 }
 
 // New returns new race object with default http client
+//
+// Deprecated: use New of github.com/mostafa-asg/race/v2
 func New() *Race {
 	return NewWithClient(http.DefaultClient)
 }
 
 // NewWithClient returns new race object with the given http client
+//
+// Deprecated: use NewWithClient of github.com/mostafa-asg/race/v2
 func NewWithClient(client *http.Client) *Race {
-	return &Race{
-		client: client,
-	}
+	// v1 races any request, v2 refuses to race non-idempotent ones by default
+	return &Race{race: race.NewWithClient(client, race.AllowNonIdempotent())}
 }
 
 // Between gets a bunch of requests and makes http request simultaneously to all of them
 // the first answer will be returned
 // if all requests failed, it will return *multierror.Error containing all errors that happened
+//
+// Deprecated: use Between of github.com/mostafa-asg/race/v2
 func Between(reqs ...*http.Request) (*http.Response, error) {
 	return New().Between(reqs...)
 }
 
 // BetweenWithClient is like Between but gets user's http client
+//
+// Deprecated: use BetweenWithClient of github.com/mostafa-asg/race/v2
 func BetweenWithClient(client *http.Client, reqs ...*http.Request) (*http.Response, error) {
 	return NewWithClient(client).Between(reqs...)
 }
 
 // FirstThenStart starts the given requests and if the given timeout elapses or
 // error happens it starts the other requests concurently
+//
+// Deprecated: use FirstThenStart of github.com/mostafa-asg/race/v2
 func FirstThenStart(first *http.Request, timeout time.Duration, reqs ...*http.Request) (*http.Response, error) {
 	return New().FirstThenStart(first, timeout, reqs...)
 }
 
-func (race *Race) makeRequest(onComplete chan *http.Response, onError chan error, req *http.Request) {
-	res, err := race.client.Do(req)
-	if err != nil {
-		onError <- err
-		return
+// v1Error returns the errors of the failed attempts of a race as v1 did, a *multierror.Error
+// of the errors of the requests in its default format, instead of the *race.AttemptError of v2
+func v1Error(err error) error {
+	var merr *multierror.Error
+	if !errors.As(err, &merr) {
+		return err
 	}
 
-	onComplete <- res
-}
-
-func createContext(timeout time.Duration) (context.Context, context.CancelFunc) {
-	if timeout > 0 {
-		return context.WithTimeout(context.Background(), timeout)
+	errs := &multierror.Error{}
+	for _, err := range merr.Errors {
+		var attempt *race.AttemptError
+		if errors.As(err, &attempt) {
+			err = attempt.Err
+		}
+		if !errors.Is(err, race.ErrNotAttempted) {
+			errs = multierror.Append(errs, err)
+		}
 	}
-
-	return context.WithCancel(context.Background())
+	return errs
 }
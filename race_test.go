@@ -2,13 +2,16 @@ package race
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"net/http"
-	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	v2 "github.com/mostafa-asg/race/v2"
+	"github.com/mostafa-asg/race/v2/racetest"
 )
 
 const unresolvableDomain = "http://CrazyAndStrangeAndUnresolvableDomain"
@@ -17,29 +20,13 @@ func TestBetweenSlowAndFast(t *testing.T) {
 	slow := []byte("slow")
 	fast := []byte("fast")
 
-	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(1 * time.Second)
-		w.Write(slow)
-	}))
-	defer slowServer.Close()
+	servers := racetest.NewServers(
+		racetest.Profile{Latency: 1 * time.Second, Body: slow},
+		racetest.Profile{Latency: 500 * time.Microsecond, Body: fast},
+	)
+	defer servers.Close()
 
-	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(500 * time.Microsecond)
-		w.Write(fast)
-	}))
-	defer fastServer.Close()
-
-	req1, err := http.NewRequest("GET", slowServer.URL, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	req2, err := http.NewRequest("GET", fastServer.URL, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	res, err := Between(req1, req2)
+	res, err := Between(servers.Requests("/")...)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -56,16 +43,10 @@ func TestBetweenSlowAndFast(t *testing.T) {
 func TestBetweenUnresolvableAndResolvableHost(t *testing.T) {
 	hello := []byte("hello")
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(1 * time.Second)
-		w.Write(hello)
-	}))
-	defer server.Close()
+	servers := racetest.NewServers(racetest.Profile{Latency: 1 * time.Second, Body: hello})
+	defer servers.Close()
 
-	req1, err := http.NewRequest("GET", server.URL, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
+	req1 := servers.Requests("/")[0]
 
 	req2, err := http.NewRequest("GET", unresolvableDomain, nil)
 	if err != nil {
@@ -89,16 +70,10 @@ func TestBetweenUnresolvableAndResolvableHost(t *testing.T) {
 func TestBetweenFailAndTimeoutReq(t *testing.T) {
 	hello := []byte("hello")
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(2 * time.Second)
-		w.Write(hello)
-	}))
-	defer server.Close()
+	servers := racetest.NewServers(racetest.Profile{Latency: 2 * time.Second, Body: hello})
+	defer servers.Close()
 
-	req1, err := http.NewRequest("GET", server.URL, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
+	req1 := servers.Requests("/")[0]
 
 	req2, err := http.NewRequest("GET", unresolvableDomain, nil)
 	if err != nil {
@@ -153,29 +128,14 @@ func TestFirstThenStart_ResponseFromSecond(t *testing.T) {
 	slow := []byte("slow")
 	fast := []byte("fast")
 
-	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(2 * time.Second)
-		w.Write(slow)
-	}))
-	defer slowServer.Close()
+	servers := racetest.NewServers(
+		racetest.Profile{Latency: 2 * time.Second, Body: slow},
+		racetest.Profile{Latency: 500 * time.Microsecond, Body: fast},
+	)
+	defer servers.Close()
 
-	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(500 * time.Microsecond)
-		w.Write(fast)
-	}))
-	defer fastServer.Close()
-
-	req1, err := http.NewRequest("GET", slowServer.URL, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	req2, err := http.NewRequest("GET", fastServer.URL, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	res, err := FirstThenStart(req1, 500*time.Microsecond, req2)
+	reqs := servers.Requests("/")
+	res, err := FirstThenStart(reqs[0], 500*time.Microsecond, reqs[1])
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -193,29 +153,14 @@ func TestFirstThenStart_ResponseFromFirst(t *testing.T) {
 	slow := []byte("slow")
 	fast := []byte("fast")
 
-	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(2 * time.Second)
-		w.Write(slow)
-	}))
-	defer fastServer.Close()
-
-	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(4 * time.Second)
-		w.Write(fast)
-	}))
-	defer slowServer.Close()
+	servers := racetest.NewServers(
+		racetest.Profile{Latency: 2 * time.Second, Body: slow},
+		racetest.Profile{Latency: 4 * time.Second, Body: fast},
+	)
+	defer servers.Close()
 
-	req1, err := http.NewRequest("GET", fastServer.URL, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	req2, err := http.NewRequest("GET", slowServer.URL, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	res, err := FirstThenStart(req1, 500*time.Microsecond, req2)
+	reqs := servers.Requests("/")
+	res, err := FirstThenStart(reqs[0], 500*time.Microsecond, reqs[1])
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -232,21 +177,15 @@ func TestFirstThenStart_ResponseFromFirst(t *testing.T) {
 func TestFirstThenStart_FirstError(t *testing.T) {
 	hello := []byte("hello")
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(500 * time.Millisecond)
-		w.Write(hello)
-	}))
-	defer server.Close()
+	servers := racetest.NewServers(racetest.Profile{Latency: 500 * time.Millisecond, Body: hello})
+	defer servers.Close()
 
 	req1, err := http.NewRequest("GET", unresolvableDomain, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	req2, err := http.NewRequest("GET", server.URL, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
+	req2 := servers.Requests("/")[0]
 
 	// yes, after 60 seconds! but we won't wait that long
 	// because after error occurs, immediately req2 will be started
@@ -294,3 +233,57 @@ func TestFirstThenStart_AllError(t *testing.T) {
 		t.Fatal("Expected 2 errors")
 	}
 }
+
+func TestBetween_Errors(t *testing.T) {
+	req1, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	req2, _ := http.NewRequest("GET", unresolvableDomain, nil)
+
+	_, err := Between(req1, req2)
+	multiError, ok := err.(*multierror.Error)
+	if !ok || len(multiError.Errors) != 2 {
+		t.Fatalf("Expected 2 errors in a *multierror.Error, got %v", err)
+	}
+	for _, err := range multiError.Errors {
+		var attempt *v2.AttemptError
+		if errors.As(err, &attempt) {
+			t.Fatalf("Expected the errors of the requests as they are, got %v", err)
+		}
+	}
+	if !strings.HasPrefix(err.Error(), "2 errors occurred:") {
+		t.Fatalf("Expected the default format of multierror, got %q", err)
+	}
+}
+
+func TestBetween_NonIdempotent(t *testing.T) {
+	servers := racetest.NewServers(racetest.Profile{}, racetest.Profile{})
+	defer servers.Close()
+
+	reqs := servers.Requests("/")
+	req1, req2 := reqs[0], reqs[1]
+	req1.Method, req2.Method = "POST", "POST"
+
+	res, err := Between(req1, req2)
+	if err != nil {
+		t.Fatalf("Expected POST requests to be raced, got %v", err)
+	}
+	res.Body.Close()
+}
+
+func TestFirstThenStart_NoOthers(t *testing.T) {
+	servers := racetest.NewServers(racetest.Profile{Latency: 100 * time.Millisecond, Body: []byte("hello")})
+	defer servers.Close()
+
+	req := servers.Requests("/")[0]
+
+	// without other requests the timeout doesn't limit the first one
+	res, err := FirstThenStart(req, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, _ := ioutil.ReadAll(res.Body)
+	if string(body) != "hello" {
+		t.Fatalf("Expected the response of the first request, got %q", body)
+	}
+}
@@ -0,0 +1,114 @@
+package race
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Adapter makes the attempts to the targets of a scheme through another protocol than HTTP,
+// e.g. the SDK of an object storage, and returns their answers as HTTP responses
+type Adapter interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// AdapterFunc is a function used as an Adapter
+type AdapterFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req)
+func (f AdapterFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// WithAdapter makes the attempts to the targets of the scheme, e.g. "s3" or "gs", by the adapter, so one
+// race can fetch the same object from an HTTP mirror and the object storages of several providers
+func WithAdapter(scheme string, adapter Adapter) Option {
+	return func(race *Race) {
+		if race.adapters == nil {
+			race.adapters = make(map[string]Adapter)
+		}
+		race.adapters[strings.ToLower(scheme)] = adapter
+	}
+}
+
+// Object is an object read from an object storage
+type Object struct {
+	// Body is the content of the object, it can be nil, e.g. for the metadata of HEAD requests
+	Body io.ReadCloser
+	// Size is the length of the body, -1 if it's unknown
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+// ObjectAdapter returns an Adapter reading the objects of GET and HEAD requests by the given function,
+// the bucket is the host of the target and the key is its path without the leading slash,
+// e.g. s3://bucket/path/to/key. A nil object means it doesn't exist, it's answered with 404
+func ObjectAdapter(get func(ctx context.Context, bucket, key string) (*Object, error)) Adapter {
+	return AdapterFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			return objectResponse(req, http.StatusMethodNotAllowed, nil), nil
+		}
+
+		obj, err := get(req.Context(), req.URL.Host, strings.TrimPrefix(req.URL.Path, "/"))
+		if err != nil {
+			return nil, err
+		}
+		if obj == nil {
+			return objectResponse(req, http.StatusNotFound, nil), nil
+		}
+		return objectResponse(req, http.StatusOK, obj), nil
+	})
+}
+
+func objectResponse(req *http.Request, status int, obj *Object) *http.Response {
+	res := &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          http.NoBody,
+		ContentLength: 0,
+		Request:       req,
+	}
+	if obj == nil {
+		return res
+	}
+
+	res.ContentLength = obj.Size
+	if obj.Size >= 0 {
+		res.Header.Set("Content-Length", strconv.FormatInt(obj.Size, 10))
+	}
+	if obj.ContentType != "" {
+		res.Header.Set("Content-Type", obj.ContentType)
+	}
+	if obj.ETag != "" {
+		res.Header.Set("ETag", obj.ETag)
+	}
+	if !obj.LastModified.IsZero() {
+		res.Header.Set("Last-Modified", obj.LastModified.UTC().Format(http.TimeFormat))
+	}
+	if obj.Body == nil {
+		return res
+	}
+	if req.Method == http.MethodHead {
+		obj.Body.Close()
+	} else {
+		res.Body = obj.Body
+	}
+	return res
+}
+
+// adapterFor returns the adapter of the scheme of the request, nil if it's made by HTTP
+func (race *Race) adapterFor(req *http.Request) Adapter {
+	if race.adapters == nil {
+		return nil
+	}
+	return race.adapters[req.URL.Scheme]
+}
@@ -0,0 +1,85 @@
+package race
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAdapter(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowServer.Close()
+
+	var bucket, key string
+	storage := ObjectAdapter(func(ctx context.Context, b, k string) (*Object, error) {
+		bucket, key = b, k
+		if k != "path/to/object" {
+			return nil, nil
+		}
+		return &Object{
+			Body:        io.NopCloser(bytes.NewReader([]byte("object"))),
+			Size:        6,
+			ContentType: "text/plain",
+			ETag:        `"v1"`,
+		}, nil
+	})
+
+	r := New(WithAdapter("s3", storage))
+	mirror, _ := http.NewRequest("GET", slowServer.URL+"/path/to/object", nil)
+	object, _ := http.NewRequest("GET", "s3://bucket/path/to/object", nil)
+	res, err := r.Between(mirror, object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if bucket != "bucket" || key != "path/to/object" {
+		t.Fatalf("Unexpected object %s/%s", bucket, key)
+	}
+	if res.StatusCode != http.StatusOK || res.Header.Get("ETag") != `"v1"` || res.ContentLength != 6 {
+		t.Fatalf("Unexpected response %+v", res)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	if string(body) != "object" {
+		t.Fatalf("Unexpected body %q", body)
+	}
+
+	missing, _ := http.NewRequest("GET", "s3://bucket/missing", nil)
+	res, err = r.Between(missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected a missing object, got %d", res.StatusCode)
+	}
+}
+
+func TestAdapter_NilBody(t *testing.T) {
+	storage := ObjectAdapter(func(ctx context.Context, b, k string) (*Object, error) {
+		return &Object{Size: 6, ETag: `"v1"`}, nil
+	})
+	r := New(WithAdapter("s3", storage))
+
+	for _, method := range []string{"HEAD", "GET"} {
+		req, _ := http.NewRequest(method, "s3://bucket/key", nil)
+		res, err := r.Between(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Body == nil || res.Header.Get("ETag") != `"v1"` {
+			t.Fatalf("Expected the metadata with an empty body for %s, got %+v", method, res)
+		}
+		res.Body.Close()
+	}
+}
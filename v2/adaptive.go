@@ -0,0 +1,61 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// adaptiveMinSamples is how many latencies of the host of the first request are needed
+// before AdaptiveFirstThenStart uses their percentile instead of the fallback delay
+const adaptiveMinSamples = 20
+
+// Latency returns the latencies of the successful attempts made to the host, the percentiles are computed
+// over the last ones and the failures aren't counted. It's the host of the URL, with the port if any
+func (race *Race) Latency(host string) Cohort {
+	race.mu.Lock()
+	defer race.mu.Unlock()
+
+	c, ok := race.hostLatencies[host]
+	if !ok {
+		return Cohort{}
+	}
+	return c.stats()
+}
+
+// recordLatency records the latency of a successful attempt made to the host
+func (race *Race) recordLatency(host string, elapsed time.Duration) {
+	race.mu.Lock()
+	defer race.mu.Unlock()
+
+	if race.hostLatencies == nil {
+		race.hostLatencies = make(map[string]*cohort)
+	}
+	c, ok := race.hostLatencies[host]
+	if !ok {
+		c = &cohort{}
+		race.hostLatencies[host] = c
+	}
+	c.add(elapsed, nil)
+}
+
+// AdaptiveFirstThenStart is like FirstThenStart but the other requests start when the first one takes
+// longer than the 95th percentile of the latency of its host, the standard way to hedge only the tail.
+// The fallback delay is used until enough attempts were made to the host, see Latency
+func (race *Race) AdaptiveFirstThenStart(first *http.Request, fallback time.Duration, reqs ...*http.Request) (*http.Response, error) {
+	return race.AdaptiveFirstThenStartContext(context.Background(), first, fallback, reqs...)
+}
+
+// AdaptiveFirstThenStartContext is like AdaptiveFirstThenStart but the race is canceled when the context is done
+func (race *Race) AdaptiveFirstThenStartContext(ctx context.Context, first *http.Request, fallback time.Duration, reqs ...*http.Request) (*http.Response, error) {
+	return race.FirstThenStartContext(ctx, first, race.hedgeDelay(first.URL.Host, fallback), reqs...)
+}
+
+// hedgeDelay returns the 95th percentile of the latency of the host, or the fallback without enough samples
+func (race *Race) hedgeDelay(host string, fallback time.Duration) time.Duration {
+	latency := race.Latency(host)
+	if latency.Races < adaptiveMinSamples {
+		return fallback
+	}
+	return latency.P95
+}
@@ -0,0 +1,57 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveFirstThenStart(t *testing.T) {
+	var stall int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&stall) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer primary.Close()
+	hedge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer hedge.Close()
+
+	r := New()
+	defer r.Close()
+
+	u, _ := url.Parse(primary.URL)
+	for i := 0; i < adaptiveMinSamples; i++ {
+		req, _ := http.NewRequest("GET", primary.URL, nil)
+		res, err := r.Between(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	latency := r.Latency(u.Host)
+	if latency.Races != adaptiveMinSamples || latency.P95 < 5*time.Millisecond {
+		t.Fatalf("Expected the latencies of the primary, got %+v", latency)
+	}
+
+	atomic.StoreInt32(&stall, 1)
+	req1, _ := http.NewRequest("GET", primary.URL, nil)
+	req2, _ := http.NewRequest("GET", hedge.URL, nil)
+	start := time.Now()
+	res, err := r.AdaptiveFirstThenStart(req1, time.Second, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Expected the hedge to start after the p95 of the primary instead of the fallback, took %v", elapsed)
+	}
+}
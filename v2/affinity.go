@@ -0,0 +1,80 @@
+package race
+
+import (
+	"net/http"
+	"sync"
+)
+
+// maxAffinityKeys bounds the memory of Affinity, a random key is forgotten beyond it
+const maxAffinityKeys = 1 << 16
+
+// Affinity returns a strategy that makes the target which won the last race of a key the
+// primary of the next races of the same key, improving the cache hit rate of the backends while
+// the other targets keep racing as the fallback. The key, e.g. a user ID or a shard key, is
+// extracted from the first target by the given function, races with an empty key have no affinity.
+// The targets are ordered by the next strategy first if it's not nil, it's also notified of the attempts.
+// Targets are identified by their host
+func Affinity(key func(target *http.Request) string, next Strategy) Strategy {
+	return &affinity{
+		chain:   chain{next: next},
+		key:     key,
+		winners: make(map[string]string),
+	}
+}
+
+type affinity struct {
+	chain
+	key func(target *http.Request) string
+
+	mu      sync.Mutex
+	winners map[string]string
+}
+
+func (a *affinity) Order(targets []*http.Request) []*http.Request {
+	ordered := a.order(targets)
+	if len(ordered) < 2 {
+		return ordered
+	}
+
+	key := a.key(ordered[0])
+	if key == "" {
+		return ordered
+	}
+	a.mu.Lock()
+	host, ok := a.winners[key]
+	a.mu.Unlock()
+	if !ok {
+		return ordered
+	}
+
+	for i, target := range ordered {
+		if target.URL.Host == host {
+			// don't modify the slice of the next strategy
+			preferred := make([]*http.Request, 0, len(ordered))
+			preferred = append(preferred, target)
+			preferred = append(preferred, ordered[:i]...)
+			return append(preferred, ordered[i+1:]...)
+		}
+	}
+	return ordered
+}
+
+func (a *affinity) Won(target *http.Request) {
+	a.chain.Won(target)
+
+	key := a.key(target)
+	if key == "" {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.winners[key]; !ok && len(a.winners) >= maxAffinityKeys {
+		for forgotten := range a.winners {
+			delete(a.winners, forgotten)
+			break
+		}
+	}
+	a.winners[key] = target.URL.Host
+}
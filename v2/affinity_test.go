@@ -0,0 +1,53 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAffinity(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowServer.Close()
+
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fastServer.Close()
+
+	user := func(target *http.Request) string { return target.Header.Get("X-User") }
+	strategy := Affinity(user, nil)
+	r := New(WithStrategy(strategy))
+	defer r.Close()
+
+	requests := func(id string) []*http.Request {
+		req1, _ := http.NewRequest("GET", slowServer.URL, nil)
+		req2, _ := http.NewRequest("GET", fastServer.URL, nil)
+		req1.Header.Set("X-User", id)
+		req2.Header.Set("X-User", id)
+		return []*http.Request{req1, req2}
+	}
+
+	res, err := r.Between(requests("alice")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	targets := requests("alice")
+	if ordered := strategy.Order(targets); ordered[0] != targets[1] || len(ordered) != 2 {
+		t.Fatal("Expected the last winner of the key to be the primary")
+	}
+	if targets[0].URL.String() != slowServer.URL {
+		t.Fatal("Expected the targets not to be modified")
+	}
+
+	targets = requests("bob")
+	if ordered := strategy.Order(targets); ordered[0] != targets[0] {
+		t.Fatal("Expected no affinity for a new key")
+	}
+}
@@ -0,0 +1,53 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// AttemptResult is the outcome of a request of All
+type AttemptResult struct {
+	Request *http.Request
+	// Response is nil when the attempt failed with Err
+	Response *http.Response
+	Err      error
+	// Elapsed is the time until the response headers arrived or the attempt failed
+	Elapsed time.Duration
+}
+
+// All makes the requests simultaneously and waits for every one of them, e.g. to compare replicas or
+// to benchmark them, instead of returning the first answer. The results are in the order of the requests
+// and the responses must be closed, the requests are canceled when the context is done
+func (race *Race) All(ctx context.Context, reqs ...*http.Request) ([]AttemptResult, error) {
+	if len(reqs) == 0 {
+		return nil, ErrNoRequests
+	}
+	if err := race.checkIdempotent(reqs); err != nil {
+		return nil, err
+	}
+
+	c := race.newContestContext(ctx, race.client.Timeout)
+	defer c.finish()
+
+	c.startAll(reqs)
+
+	results := make([]AttemptResult, len(reqs))
+	for range reqs {
+		o := <-c.results
+		result := &results[o.index]
+		result.Request = reqs[o.index]
+		result.Elapsed = time.Since(c.started)
+		if o.err != nil {
+			result.Err = o.err
+			continue
+		}
+		result.Response = c.keep(o.index, o.res)
+	}
+	return results, nil
+}
+
+// All makes the requests simultaneously and waits for every one of them
+func All(ctx context.Context, reqs ...*http.Request) ([]AttemptResult, error) {
+	return New().All(ctx, reqs...)
+}
@@ -0,0 +1,43 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAll(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	req1, _ := http.NewRequest("GET", slow.URL, nil)
+	req2, _ := http.NewRequest("GET", fast.URL, nil)
+	req3, _ := http.NewRequest("GET", unresolvableDomain, nil)
+
+	results, err := All(context.Background(), req1, req2, req3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	for i, req := range []*http.Request{req1, req2} {
+		result := results[i]
+		if result.Err != nil || result.Response == nil || result.Request != req {
+			t.Fatalf("Expected the response of request %d, got %+v", i, result)
+		}
+		result.Response.Body.Close()
+	}
+	if results[0].Elapsed < 50*time.Millisecond || results[1].Elapsed >= results[0].Elapsed {
+		t.Fatalf("Expected the slow request to take longer, got %v and %v", results[0].Elapsed, results[1].Elapsed)
+	}
+	if results[2].Err == nil || results[2].Response != nil {
+		t.Fatalf("Expected the error of the failing request, got %+v", results[2])
+	}
+}
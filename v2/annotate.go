@@ -0,0 +1,39 @@
+package race
+
+import "net/http"
+
+// DefaultAnnotationHeader is the header used by WithAnnotation when no name is given
+const DefaultAnnotationHeader = "X-Race-Role"
+
+const (
+	// RolePrimary is the annotation of a response that came from the primary target
+	RolePrimary = "primary"
+	// RoleHedge is the annotation of a response that came from any other target
+	RoleHedge = "hedge"
+)
+
+// WithAnnotation adds a header to the winning response saying whether it came from
+// a primary (the first target of the race, or the first tier when others follow it) or a hedge,
+// so downstream caches and analytics can segregate hedge-derived entries.
+// The header defaults to DefaultAnnotationHeader
+func WithAnnotation(header string) Option {
+	if header == "" {
+		header = DefaultAnnotationHeader
+	}
+
+	return WithProcessor(func(res *http.Response, index int) (*http.Response, error) {
+		hedge := index > 0
+		if res.Request != nil {
+			hedge = priorityOf(res.Request.Context()).hedge
+		}
+		role := RolePrimary
+		if hedge {
+			role = RoleHedge
+		}
+		if res.Header == nil {
+			res.Header = make(http.Header)
+		}
+		res.Header.Set(header, role)
+		return res, nil
+	})
+}
@@ -0,0 +1,58 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAnnotation(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer slowServer.Close()
+
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fastServer.Close()
+
+	slow, err := http.NewRequest("GET", slowServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fast, err := http.NewRequest("GET", fastServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(WithAnnotation(""))
+
+	res, err := r.FirstThenStart(fast, time.Second, slow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if role := res.Header.Get(DefaultAnnotationHeader); role != RolePrimary {
+		t.Fatalf("Expected %q, got %q", RolePrimary, role)
+	}
+
+	res, err = r.FirstThenStart(slow, 10*time.Millisecond, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if role := res.Header.Get(DefaultAnnotationHeader); role != RoleHedge {
+		t.Fatalf("Expected %q, got %q", RoleHedge, role)
+	}
+
+	// the second primary wins
+	res, err = r.PrimariesThenStart([]*http.Request{slow, fast}, time.Second, slow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if role := res.Header.Get(DefaultAnnotationHeader); role != RolePrimary {
+		t.Fatalf("Expected %q, got %q", RolePrimary, role)
+	}
+}
@@ -0,0 +1,96 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Attempt is a request of a race with its own behavior, so targets behind different
+// backends can be raced together, see Run
+type Attempt struct {
+	Request *http.Request
+	// Client sends the request instead of the client of the Race or of its target, it can be nil
+	Client *http.Client
+	// Timeout limits the attempt including reading its body, zero means no limit
+	Timeout time.Duration
+	// Retries is how many more times the request is sent when it fails, while the race lasts and
+	// its attempt budget allows. A request with a body is only retried if it has GetBody
+	Retries int
+	// Validator is applied to the responses after the validators of the Race, it can be nil
+	Validator Validator
+}
+
+type attemptKey struct{}
+
+// Run makes the attempts simultaneously and returns the Result of the first one to succeed,
+// if the Race has a Strategy it decides which of the attempts are made and in which order
+func (race *Race) Run(ctx context.Context, attempts []Attempt) (Result, error) {
+	options := make(map[*http.Request]*Attempt, len(attempts))
+	reqs := make([]*http.Request, len(attempts))
+	for i := range attempts {
+		options[attempts[i].Request] = &attempts[i]
+		reqs[i] = attempts[i].Request
+	}
+
+	reqs = race.order(reqs)
+	if len(reqs) == 0 {
+		return Result{Err: ErrNoRequests}, ErrNoRequests
+	}
+
+	c := race.newContestContext(ctx, race.client.Timeout)
+	c.options = options
+	defer c.finish()
+
+	res, err := c.run([]Tier{{Requests: reqs}})
+	return c.result(reqs, res, err)
+}
+
+// Run makes the attempts simultaneously and returns the Result of the first one to succeed
+func Run(ctx context.Context, attempts []Attempt) (Result, error) {
+	return New().Run(ctx, attempts)
+}
+
+// attemptContext returns the context of the attempt of the request, derived from the contest
+func (c *contest) attemptContext(req *http.Request) (context.Context, context.CancelCauseFunc) {
+	ctx := withTarget(c.ctx, req)
+	attempt, ok := c.options[req]
+	if !ok {
+		return context.WithCancelCause(ctx)
+	}
+	return createContext(context.WithValue(ctx, attemptKey{}, attempt), attempt.Timeout)
+}
+
+// attemptOf returns the Attempt the context of a request belongs to, nil if the request wasn't given by one
+func attemptOf(ctx context.Context) *Attempt {
+	attempt, _ := ctx.Value(attemptKey{}).(*Attempt)
+	return attempt
+}
+
+// doAttempt makes the attempt, sending the request again on errors as many times as its Attempt allows
+func (race *Race) doAttempt(req *http.Request) (*http.Response, error) {
+	res, err := race.doForTenant(req)
+
+	attempt := attemptOf(req.Context())
+	if attempt == nil {
+		return res, err
+	}
+	for retry := 0; err != nil && retry < attempt.Retries && req.Context().Err() == nil; retry++ {
+		if spendAttempt(req.Context()) != nil {
+			break
+		}
+		again := req.Clone(req.Context())
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				break
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				break
+			}
+			again.Body = body
+		}
+		res, err = race.doForTenant(again)
+	}
+	return res, err
+}
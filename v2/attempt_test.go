@@ -0,0 +1,83 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingTransport struct {
+	calls int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.calls, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRun(t *testing.T) {
+	var calls int32
+	flakyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer flakyServer.Close()
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowServer.Close()
+
+	flaky, _ := http.NewRequest("GET", flakyServer.URL, nil)
+	slow, _ := http.NewRequest("GET", slowServer.URL, nil)
+	transport := &countingTransport{}
+
+	result, err := Run(context.Background(), []Attempt{
+		{Request: slow, Timeout: 50 * time.Millisecond},
+		{
+			Request:   flaky,
+			Client:    &http.Client{Transport: transport},
+			Retries:   2,
+			Validator: SuccessfulStatus,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Response.Body.Close()
+
+	if result.Request != flaky {
+		t.Fatal("Expected the flaky server to win after its retries")
+	}
+	if atomic.LoadInt32(&transport.calls) != 3 {
+		t.Fatalf("Expected 3 requests by the client of the attempt, got %d", transport.calls)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowServer.Close()
+
+	slow, _ := http.NewRequest("GET", slowServer.URL, nil)
+	start := time.Now()
+	_, err := Run(context.Background(), []Attempt{{Request: slow, Timeout: 50 * time.Millisecond}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the attempt to time out, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("Expected the attempt to be limited by its timeout")
+	}
+}
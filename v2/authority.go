@@ -0,0 +1,76 @@
+package race
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WithAuthority declares the hosts of mirrors serving the same resources as the authority, e.g.
+// WithAuthority("cdn.example", "mirror1.example", "mirror2.example:8443"), so the results shared by
+// Warm and WithCoalescing are keyed by resource instead of by mirror URL: the race of a resource is
+// answered by the result of another race of it whichever mirrors they use, and a warm result fetched
+// from a mirror is revalidated against any of them. The authority itself can be one of the mirrors
+func WithAuthority(authority string, mirrors ...string) Option {
+	return func(race *Race) {
+		if race.authorities == nil {
+			race.authorities = make(map[string]string)
+		}
+		race.authorities[authority] = authority
+		for _, mirror := range mirrors {
+			race.authorities[mirror] = authority
+		}
+	}
+}
+
+// resourceURL returns the URL of the resource served by the URL, the host of a mirror is replaced by
+// its authority, without the scheme since mirrors may not share it
+func (race *Race) resourceURL(u *url.URL) *url.URL {
+	authority, ok := race.authorities[u.Host]
+	if !ok {
+		return u
+	}
+
+	resource := *u
+	resource.Scheme = ""
+	resource.Host = authority
+	return &resource
+}
+
+// revalidate returns the requests made conditional on the validators of the previous result, so a
+// mirror answers 304 Not Modified if it has the same representation, whichever mirror sent it
+func revalidate(reqs []*http.Request, previous *warmed) []*http.Request {
+	if previous == nil {
+		return reqs
+	}
+	etag := previous.res.Header.Get("ETag")
+	lastModified := previous.res.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return reqs
+	}
+
+	conditional := make([]*http.Request, len(reqs))
+	for i, req := range reqs {
+		conditional[i] = req.Clone(req.Context())
+		if etag != "" {
+			conditional[i].Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			conditional[i].Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+	return conditional
+}
+
+// hasDirective reports whether the Cache-Control header has the directive
+func hasDirective(header http.Header, directive string) bool {
+	for _, value := range header.Values("Cache-Control") {
+		for _, d := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(d), "=")
+			if strings.EqualFold(name, directive) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package race
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAuthorityRevalidation(t *testing.T) {
+	mirrorA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("from a"))
+	}))
+	defer mirrorA.Close()
+	var revalidated int32
+	mirrorB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&revalidated, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("from b"))
+	}))
+	defer mirrorB.Close()
+	var hitsC int32
+	mirrorC := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hitsC, 1)
+	}))
+	defer mirrorC.Close()
+
+	host := func(server *httptest.Server) string {
+		u, _ := url.Parse(server.URL)
+		return u.Host
+	}
+	r := New(WithAuthority("cdn.example", host(mirrorA), host(mirrorB), host(mirrorC)))
+	defer r.Close()
+
+	reqA, _ := http.NewRequest("GET", mirrorA.URL+"/logo.png", nil)
+	reqB, _ := http.NewRequest("GET", mirrorB.URL+"/logo.png", nil)
+	key, _ := r.raceKey([]*http.Request{reqA})
+	if keyB, _ := r.raceKey([]*http.Request{reqB, reqB}); keyB != key {
+		t.Fatalf("Expected the mirrors to share the key of the resource, got %q and %q", key, keyB)
+	}
+
+	r.warm(context.Background(), key, time.Minute, []*http.Request{reqA})
+	r.warm(context.Background(), key, time.Minute, []*http.Request{reqB})
+	if atomic.LoadInt32(&revalidated) != 1 {
+		t.Fatal("Expected the result of mirror a to be revalidated against mirror b")
+	}
+
+	reqC, _ := http.NewRequest("GET", mirrorC.URL+"/logo.png", nil)
+	res, err := r.Between(reqC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "from a" || atomic.LoadInt32(&hitsC) != 0 {
+		t.Fatalf("Expected the revalidated result of mirror a, got %q", body)
+	}
+}
+
+func TestWarmNoStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "private, no-store")
+	}))
+	defer server.Close()
+
+	r := New()
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	key, _ := r.raceKey([]*http.Request{req})
+	r.warm(context.Background(), key, time.Minute, []*http.Request{req})
+	if _, ok := r.warmResponse([]*http.Request{req}); ok {
+		t.Fatal("Expected a no-store response not to be kept")
+	}
+}
+
+func TestWarmRevalidation_SuccessfulStatus(t *testing.T) {
+	var conditional int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&conditional, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("v1"))
+	}))
+	defer server.Close()
+
+	r := New(WithValidator(SuccessfulStatus))
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	key, _ := r.raceKey([]*http.Request{req})
+	r.warm(context.Background(), key, time.Minute, []*http.Request{req})
+	r.mu.Lock()
+	r.warmed[key].expires = time.Now().Add(time.Millisecond)
+	r.mu.Unlock()
+
+	r.warm(context.Background(), key, time.Minute, []*http.Request{req})
+	if atomic.LoadInt32(&conditional) != 1 {
+		t.Fatal("Expected the result to be revalidated")
+	}
+	time.Sleep(5 * time.Millisecond)
+	res, ok := r.warmResponse([]*http.Request{req})
+	if !ok {
+		t.Fatal("Expected the 304 to refresh the result despite the validator")
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	if string(body) != "v1" {
+		t.Fatalf("Expected the revalidated result, got %q", body)
+	}
+}
+
+func TestWarmUnsuccessful(t *testing.T) {
+	var failing int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	r := New()
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	key, _ := r.raceKey([]*http.Request{req})
+	atomic.StoreInt32(&failing, 1)
+	r.warm(context.Background(), key, time.Minute, []*http.Request{req})
+	if _, ok := r.warmResponse([]*http.Request{req}); ok {
+		t.Fatal("Expected an unsuccessful response not to be kept")
+	}
+
+	atomic.StoreInt32(&failing, 0)
+	r.warm(context.Background(), key, time.Minute, []*http.Request{req})
+	atomic.StoreInt32(&failing, 1)
+	r.warm(context.Background(), key, time.Minute, []*http.Request{req})
+	res, ok := r.warmResponse([]*http.Request{req})
+	if !ok || res.StatusCode != http.StatusOK {
+		t.Fatal("Expected the previous result to be kept")
+	}
+}
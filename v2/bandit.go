@@ -0,0 +1,48 @@
+package race
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// EpsilonGreedy returns a bandit strategy that learns online which targets have the lowest
+// latency. Each race has a primary and at most the given number of hedges, which is the load
+// budget of the race: usually they are the fastest known targets, but with the probability
+// epsilon (between 0 and 1) the primary is a random target, so the strategy keeps learning.
+// Negative hedges are treated as none. Targets are identified by their host
+func EpsilonGreedy(epsilon float64, hedges int) Strategy {
+	if hedges < 0 {
+		hedges = 0
+	}
+	return &epsilonGreedy{
+		epsilon:   epsilon,
+		hedges:    hedges,
+		latencies: newLatencies(),
+	}
+}
+
+type epsilonGreedy struct {
+	epsilon   float64
+	hedges    int
+	latencies *latencies
+}
+
+func (eg *epsilonGreedy) Order(targets []*http.Request) []*http.Request {
+	sorted := eg.latencies.sorted(targets)
+	if len(sorted) > 1 && rand.Float64() < eg.epsilon {
+		i := rand.Intn(len(sorted))
+		sorted[0], sorted[i] = sorted[i], sorted[0]
+	}
+
+	if len(sorted) > eg.hedges+1 {
+		sorted = sorted[:eg.hedges+1]
+	}
+	return sorted
+}
+
+func (eg *epsilonGreedy) Started(target *http.Request) {}
+
+func (eg *epsilonGreedy) Finished(target *http.Request, elapsed time.Duration, err error) {
+	eg.latencies.observe(target.URL.Host, elapsed, err)
+}
@@ -0,0 +1,66 @@
+package race
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEpsilonGreedy(t *testing.T) {
+	var targets []*http.Request
+	for _, host := range []string{"http://slow", "http://fast", "http://medium"} {
+		req, err := http.NewRequest("GET", host, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, req)
+	}
+
+	greedy := EpsilonGreedy(0, 1)
+	observer := greedy.(AttemptObserver)
+	observer.Finished(targets[0], 300*time.Millisecond, nil)
+	observer.Finished(targets[1], 10*time.Millisecond, nil)
+	observer.Finished(targets[2], 50*time.Millisecond, nil)
+
+	ordered := greedy.Order(targets)
+	if len(ordered) != 2 || ordered[0] != targets[1] || ordered[1] != targets[2] {
+		t.Fatal("Expected the fastest target as the primary and the next one as the hedge")
+	}
+
+	// always exploring, so every target becomes the primary at some point
+	random := EpsilonGreedy(1, 2)
+	primaries := make(map[*http.Request]bool)
+	for i := 0; i < 100; i++ {
+		ordered := random.Order(targets)
+		if len(ordered) != 3 {
+			t.Fatalf("Expected 3 targets, got %d", len(ordered))
+		}
+		primaries[ordered[0]] = true
+	}
+	if len(primaries) != 3 {
+		t.Fatalf("Expected every target to be the primary, got %d", len(primaries))
+	}
+}
+
+func TestEpsilonGreedy_Hedges(t *testing.T) {
+	var targets []*http.Request
+	for _, host := range []string{"http://a", "http://b"} {
+		req, err := http.NewRequest("GET", host, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, req)
+	}
+
+	for _, hedges := range []int{-2, -1, 0} {
+		if n := len(EpsilonGreedy(0, hedges).Order(targets)); n != 1 {
+			t.Fatalf("Expected the primary only for %d hedges, got %d", hedges, n)
+		}
+	}
+	if n := len(EpsilonGreedy(0, 5).Order(targets)); n != 2 {
+		t.Fatalf("Expected all the targets, got %d", n)
+	}
+	if n := len(EpsilonGreedy(1, -1).Order(nil)); n != 0 {
+		t.Fatalf("Expected no targets, got %d", n)
+	}
+}
@@ -0,0 +1,43 @@
+package race
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// replayableBody returns a function returning a new copy of the body of the request, the GetBody of the
+// request or a reader of the body read in memory if it has none. It returns nil if the request has no body
+func replayableBody(req *http.Request) (func() (io.ReadCloser, error), error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		return req.GetBody, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	return func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}, nil
+}
+
+// withBody gives the copy of a request its own copy of the body, so every attempt sends the whole payload
+func withBody(clone *http.Request, getBody func() (io.ReadCloser, error)) error {
+	if getBody == nil {
+		return nil
+	}
+
+	body, err := getBody()
+	if err != nil {
+		return err
+	}
+	clone.Body = body
+	clone.GetBody = getBody
+	return nil
+}
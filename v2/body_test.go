@@ -0,0 +1,65 @@
+package race
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBodyCopies(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	// a reader without GetBody is read in memory
+	req, _ := http.NewRequest("PUT", "http://placeholder/object", io.MultiReader(strings.NewReader("pay"), strings.NewReader("load")))
+	r := New()
+	defer r.Close()
+	reqs, err := r.targetRequests(req, []Target{{URL: server.URL}, {URL: "http://" + host}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range reqs {
+		res, err := r.Between(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	req, _ = http.NewRequest("PUT", "http://placeholder/object", strings.NewReader("payload"))
+	clone, err := Target{URL: server.URL}.Clone(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.Between(clone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(bodies))
+	}
+	for _, body := range bodies {
+		if body != "payload" {
+			t.Fatalf("Expected every copy to send the whole payload, got %q", body)
+		}
+	}
+	if body, _ := ioutil.ReadAll(req.Body); string(body) != "payload" {
+		t.Fatal("Expected the body of the request not to be read by its copy")
+	}
+}
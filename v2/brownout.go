@@ -0,0 +1,118 @@
+package race
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// brownoutWeight is the weight of the last attempt in the error rate of the brownout
+const brownoutWeight = 0.1
+
+// Brownout reduces hedging while the targets are in distress, so the races don't turn into a retry storm
+type Brownout struct {
+	// ErrorRate is the fraction of failed attempts (between 0 and 1) above which the brownout starts,
+	// zero means only Retry-After signals start it. The attempts fail with an error, a 5xx or a 429
+	ErrorRate float64
+	// MaxHedges is the number of hedges of the races during the brownout
+	MaxHedges int
+	// DelayFactor multiplies the delays before the next tiers during the brownout,
+	// e.g. the timeout of FirstThenStart, values below 1 are ignored
+	DelayFactor float64
+	// OnChange is called when the brownout starts or ends, it can be nil
+	OnChange func(active bool)
+}
+
+type brownout struct {
+	Brownout
+
+	mu     sync.Mutex
+	rate   float64
+	until  time.Time
+	active bool
+}
+
+// WithBrownout reduces hedging when the error rate of the attempts crosses the threshold or a
+// target answers with a Retry-After header, until the error rate falls back and Retry-After elapses
+func WithBrownout(b Brownout) Option {
+	return func(race *Race) {
+		race.brownout = &brownout{Brownout: b}
+	}
+}
+
+// observe records the attempt for the error rate
+func (b *brownout) observe(res *http.Response, err error) {
+	// the losers that are canceled didn't fail
+	if canceled(err) {
+		return
+	}
+
+	failed := 0.0
+	if err != nil || res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests {
+		failed = 1
+	}
+
+	b.mu.Lock()
+	b.rate = brownoutWeight*failed + (1-brownoutWeight)*b.rate
+	if res != nil {
+		if until, ok := retryAfter(res.Header.Get("Retry-After")); ok && until.After(b.until) {
+			b.until = until
+		}
+	}
+	b.mu.Unlock()
+
+	b.check()
+}
+
+// check reports whether the brownout is active, calling OnChange when it starts or ends
+func (b *brownout) check() bool {
+	b.mu.Lock()
+	active := (b.ErrorRate > 0 && b.rate > b.ErrorRate) || time.Now().Before(b.until)
+	changed := active != b.active
+	b.active = active
+	b.mu.Unlock()
+
+	if changed && b.OnChange != nil {
+		b.OnChange(active)
+	}
+	return active
+}
+
+// reduce applies the brownout to the tiers of a race
+func (b *brownout) reduce(tiers []Tier) []Tier {
+	if b == nil || !b.check() {
+		return tiers
+	}
+
+	reduced := make([]Tier, 0, len(tiers))
+	remaining := b.MaxHedges + 1
+	for _, tier := range tiers {
+		if remaining <= 0 {
+			break
+		}
+		if len(tier.Requests) > remaining {
+			tier.Requests = tier.Requests[:remaining]
+		}
+		remaining -= len(tier.Requests)
+		if b.DelayFactor > 1 {
+			tier.Timeout = time.Duration(float64(tier.Timeout) * b.DelayFactor)
+		}
+		reduced = append(reduced, tier)
+	}
+	return reduced
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as a date
+func retryAfter(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Now().Add(time.Duration(seconds) * time.Second), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date, true
+	}
+	return time.Time{}, false
+}
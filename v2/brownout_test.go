@@ -0,0 +1,81 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBrownout(t *testing.T) {
+	var distressed int32 = 1
+	var hits int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/counted" {
+			atomic.AddInt32(&hits, 1)
+		}
+		if atomic.LoadInt32(&distressed) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	server1 := httptest.NewServer(handler)
+	defer server1.Close()
+	server2 := httptest.NewServer(handler)
+	defer server2.Close()
+	server3 := httptest.NewServer(handler)
+	defer server3.Close()
+
+	changes := make(chan bool, 2)
+	r := New(WithBrownout(Brownout{
+		ErrorRate: 0.5,
+		MaxHedges: 0,
+		OnChange:  func(active bool) { changes <- active },
+	}))
+	defer r.Close()
+
+	race := func(path string) {
+		req1, _ := http.NewRequest("GET", server1.URL+path, nil)
+		req2, _ := http.NewRequest("GET", server2.URL+path, nil)
+		req3, _ := http.NewRequest("GET", server3.URL+path, nil)
+		res, err := r.Between(req1, req2, req3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	race("/")
+	if active := <-changes; !active {
+		t.Fatal("Expected the brownout to start on Retry-After")
+	}
+
+	// the losers of the first race may still reach the servers
+	atomic.StoreInt32(&distressed, 0)
+	race("/counted")
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Fatalf("Expected no hedges during the brownout, got %d hits", n)
+	}
+
+	time.Sleep(time.Second)
+	race("/")
+	select {
+	case active := <-changes:
+		if active {
+			t.Fatal("Expected the brownout to end")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the brownout to end once Retry-After elapsed")
+	}
+}
+
+func TestBrownoutReduce(t *testing.T) {
+	reqs := make([]*http.Request, 4)
+	b := &brownout{Brownout: Brownout{MaxHedges: 1, DelayFactor: 3}, until: time.Now().Add(time.Minute)}
+
+	tiers := b.reduce([]Tier{{Requests: reqs[:1], Timeout: time.Second}, {Requests: reqs[1:]}})
+	if len(tiers) != 2 || len(tiers[1].Requests) != 1 || tiers[0].Timeout != 3*time.Second {
+		t.Fatalf("Unexpected tiers %+v", tiers)
+	}
+}
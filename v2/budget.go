@@ -0,0 +1,51 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrBudgetExceeded is the error of the attempts that were not made because the request
+// they serve has used up its budget, see ContextWithAttemptBudget
+var ErrBudgetExceeded = errors.New("race: attempt budget exceeded")
+
+type budgetKey struct{}
+
+// attemptBudget is the number of attempts left to a request
+type attemptBudget struct {
+	left int64
+}
+
+// ContextWithAttemptBudget returns a context limiting the attempts of all the races started with it or with
+// the contexts derived from it, including the races nested in their attempts and the retries, to max,
+// e.g. 6 backend calls per user request. Once it's used up, the attempts fail with ErrBudgetExceeded without being
+// made, so the primaries made first still count and the hedges are dropped. It's meant to be given
+// the context of an incoming request
+func ContextWithAttemptBudget(parent context.Context, max int) context.Context {
+	return context.WithValue(parent, budgetKey{}, &attemptBudget{left: int64(max)})
+}
+
+// RemainingAttempts returns the number of attempts left to the context, ok is false if it has no budget
+func RemainingAttempts(ctx context.Context) (left int, ok bool) {
+	budget, ok := ctx.Value(budgetKey{}).(*attemptBudget)
+	if !ok {
+		return 0, false
+	}
+	if left := atomic.LoadInt64(&budget.left); left > 0 {
+		return int(left), true
+	}
+	return 0, true
+}
+
+// spendAttempt takes an attempt from the budget of the context, if it has one
+func spendAttempt(ctx context.Context) error {
+	budget, ok := ctx.Value(budgetKey{}).(*attemptBudget)
+	if !ok {
+		return nil
+	}
+	if atomic.AddInt64(&budget.left, -1) < 0 {
+		return ErrBudgetExceeded
+	}
+	return nil
+}
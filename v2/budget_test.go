@@ -0,0 +1,92 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAttemptBudget(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	requests := func(n int) []*http.Request {
+		reqs := make([]*http.Request, n)
+		for i := range reqs {
+			reqs[i], _ = http.NewRequest("GET", server.URL, nil)
+		}
+		return reqs
+	}
+
+	ctx := ContextWithAttemptBudget(context.Background(), 3)
+	r := New()
+	defer r.Close()
+
+	res, err := r.BetweenContext(ctx, requests(2)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	// only the primary is left in the budget
+	result, err := r.BetweenResult(ctx, requests(3)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.Response.Body.Close()
+	if result.Index != 0 {
+		t.Fatalf("Expected the primary to be made, got %d", result.Index)
+	}
+	if left, ok := RemainingAttempts(ctx); !ok || left != 0 {
+		t.Fatalf("Expected the budget to be used up, got %d", left)
+	}
+
+	_, err = r.BetweenContext(ctx, requests(2)...)
+	if !errors.Is(err, ErrBudgetExceeded) || Classify(err) != FailureBudgetExceeded {
+		t.Fatalf("Expected the budget to be exceeded, got %v", err)
+	}
+	if hits := atomic.LoadInt32(&hits); hits > 3 {
+		t.Fatalf("Expected at most 3 requests, got %d", hits)
+	}
+}
+
+func TestAttemptBudget_Retries(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	r := New()
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if _, err := r.Run(ContextWithAttemptBudget(context.Background(), 3), []Attempt{{Request: req, Retries: 5}}); err == nil {
+		t.Fatal("Expected the attempt to fail")
+	}
+	if hits := atomic.LoadInt32(&hits); hits != 3 {
+		t.Fatalf("Expected the retries to stop with the budget, got %d requests", hits)
+	}
+
+	atomic.StoreInt32(&hits, 0)
+	retrying := New(WithConnectionRetry())
+	defer retrying.Close()
+	if _, err := retrying.BetweenContext(ContextWithAttemptBudget(context.Background(), 1), req); err == nil {
+		t.Fatal("Expected the attempt to fail")
+	}
+	if hits := atomic.LoadInt32(&hits); hits != 1 {
+		t.Fatalf("Expected the connection retry to be charged to the budget, got %d requests", hits)
+	}
+}
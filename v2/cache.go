@@ -0,0 +1,63 @@
+package race
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCacheMiss is the error of the lookups that miss when misses fail, see Lookup
+var ErrCacheMiss = errors.New("race: cache miss")
+
+// Cache is a cache endpoint, e.g. the Redis or memcached client of a zone
+type Cache interface {
+	// Get returns the value of the key, found is false when it misses
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+}
+
+// CacheFunc is a function used as a Cache
+type CacheFunc func(ctx context.Context, key string) ([]byte, bool, error)
+
+// Get calls f(ctx, key)
+func (f CacheFunc) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return f(ctx, key)
+}
+
+// MissPolicy tells how Lookup treats the caches missing the key
+type MissPolicy int
+
+const (
+	// MissFails makes a miss fail with ErrCacheMiss, the lookup waits for a hit from another cache
+	MissFails MissPolicy = iota
+	// MissWins makes a miss an answer like a hit, e.g. when the caches are replicas and a miss is final
+	MissWins
+)
+
+// cacheAnswer is the answer of a cache to a lookup
+type cacheAnswer struct {
+	value []byte
+	found bool
+}
+
+// Lookup looks the key up in the caches simultaneously, e.g. the caches of several zones, and returns the
+// first answer, the other lookups are canceled. With MissFails only a hit is an answer and the lookup fails
+// with ErrCacheMiss among the errors of the caches if all of them miss or fail
+func Lookup(ctx context.Context, key string, misses MissPolicy, caches ...Cache) (value []byte, found bool, err error) {
+	fns := make([]func(ctx context.Context) (cacheAnswer, error), len(caches))
+	for i, cache := range caches {
+		cache := cache
+		fns[i] = func(ctx context.Context) (cacheAnswer, error) {
+			value, found, err := cache.Get(ctx, key)
+			if err == nil && !found && misses == MissFails {
+				err = ErrCacheMiss
+			}
+			return cacheAnswer{value: value, found: found}, err
+		}
+	}
+
+	answer, _, cancel, err := first(ctx, "cache", fns, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	cancel()
+	return answer.value, answer.found, nil
+}
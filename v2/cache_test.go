@@ -0,0 +1,43 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	cache := func(delay time.Duration, value string) Cache {
+		return CacheFunc(func(ctx context.Context, key string) ([]byte, bool, error) {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil, false, ctx.Err()
+			}
+			if value == "" {
+				return nil, false, nil
+			}
+			return []byte(value), true, nil
+		})
+	}
+	missing := cache(0, "")
+	hit := cache(20*time.Millisecond, "value")
+	slow := cache(time.Second, "value")
+
+	value, found, err := Lookup(context.Background(), "key", MissFails, missing, hit, slow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || string(value) != "value" {
+		t.Fatalf("Expected the hit, got %q", value)
+	}
+
+	if _, found, err := Lookup(context.Background(), "key", MissWins, missing, hit); err != nil || found {
+		t.Fatalf("Expected the miss to win, got %v %v", found, err)
+	}
+
+	if _, _, err := Lookup(context.Background(), "key", MissFails, missing, missing); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("Expected ErrCacheMiss, got %v", err)
+	}
+}
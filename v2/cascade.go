@@ -0,0 +1,70 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+)
+
+// Tier is a group of requests in a cascade, all the requests of a tier start together
+type Tier struct {
+	Requests []*http.Request
+	// Timeout is how long to wait for the tier before starting the next one,
+	// it's ignored for the last tier
+	Timeout time.Duration
+}
+
+// Cascade starts the requests of the first tier and if its timeout elapses or
+// all the started requests fail it adds the next tier to the race, and so on.
+// The first answer from any of the tiers will be returned
+func (race *Race) Cascade(tiers ...Tier) (*http.Response, error) {
+	return race.CascadeContext(context.Background(), tiers...)
+}
+
+// CascadeContext is like Cascade but the race is canceled when the context is done
+func (race *Race) CascadeContext(ctx context.Context, tiers ...Tier) (*http.Response, error) {
+	c := race.newContestContext(ctx, 0)
+	defer c.finish()
+
+	return c.run(tiers)
+}
+
+// Cascade starts the tiers one after another, e.g. a region, then its DR region and then the static origin,
+// the first answer from any of the tiers will be returned
+func Cascade(tiers ...Tier) (*http.Response, error) {
+	return New().Cascade(tiers...)
+}
+
+// CascadeContext is like Cascade but the race is canceled when the context is done
+func CascadeContext(ctx context.Context, tiers ...Tier) (*http.Response, error) {
+	return New().CascadeContext(ctx, tiers...)
+}
+
+// ErrInvalidCascade is the error of NewExponentialCascade for a non-positive baseDelay or a factor below 1
+var ErrInvalidCascade = errors.New("race: the base delay of a cascade must be positive and its factor at least 1")
+
+// maxTierTimeout caps the timeouts of the tiers of NewExponentialCascade, so they can't overflow
+const maxTierTimeout = 24 * time.Hour
+
+// NewExponentialCascade returns one tier per target keeping their order, the timeout
+// of the first tier is baseDelay and each next one is multiplied by factor,
+// e.g. 50ms, 100ms, 200ms... for a baseDelay of 50ms and a factor of 2, up to a day
+func NewExponentialCascade(targets []*http.Request, baseDelay time.Duration, factor float64) ([]Tier, error) {
+	if baseDelay <= 0 || !(factor >= 1) {
+		return nil, ErrInvalidCascade
+	}
+
+	tiers := make([]Tier, len(targets))
+	delay := float64(baseDelay)
+	for i, target := range targets {
+		tiers[i] = Tier{
+			Requests: []*http.Request{target},
+			Timeout:  time.Duration(delay),
+		}
+		delay = math.Min(delay*factor, float64(maxTierTimeout))
+	}
+
+	return tiers, nil
+}
@@ -0,0 +1,175 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+func TestCascade_ResponseFromLastTier(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(1 * time.Second)
+		w.Write([]byte("slow"))
+	}))
+	defer slowServer.Close()
+
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fast"))
+	}))
+	defer fastServer.Close()
+
+	req1, err := http.NewRequest("GET", slowServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req2, err := http.NewRequest("GET", slowServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req3, err := http.NewRequest("GET", fastServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := New().Cascade(
+		Tier{Requests: []*http.Request{req1}, Timeout: 20 * time.Millisecond},
+		Tier{Requests: []*http.Request{req2}, Timeout: 20 * time.Millisecond},
+		Tier{Requests: []*http.Request{req3}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	resBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(resBytes) != "fast" {
+		t.Fatalf("Expected the response of the last tier, got %q", resBytes)
+	}
+}
+
+func TestCascade_AllError(t *testing.T) {
+	var tiers []Tier
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest("GET", unresolvableDomain, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the next tier starts as soon as the previous one fails
+		tiers = append(tiers, Tier{Requests: []*http.Request{req}, Timeout: 60 * time.Second})
+	}
+
+	res, err := New().Cascade(tiers...)
+	if res != nil {
+		t.Fatal("There should be no response")
+	}
+
+	multiError, ok := err.(*multierror.Error)
+	if !ok {
+		t.Fatal("Expected error of type *multierror.Error")
+	}
+
+	if len(multiError.Errors) != 3 {
+		t.Fatal("Expected 3 errors")
+	}
+}
+
+func TestCascade_NoRequests(t *testing.T) {
+	if _, err := New().Cascade(); err != ErrNoRequests {
+		t.Fatalf("Expected ErrNoRequests, got %v", err)
+	}
+}
+
+func TestCascadeContext(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowServer.Close()
+
+	region, _ := http.NewRequest("GET", slowServer.URL, nil)
+	dr, _ := http.NewRequest("GET", slowServer.URL, nil)
+	origin, _ := http.NewRequest("GET", slowServer.URL, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := CascadeContext(ctx,
+		Tier{Requests: []*http.Request{region}, Timeout: 20 * time.Millisecond},
+		Tier{Requests: []*http.Request{dr}, Timeout: 20 * time.Millisecond},
+		Tier{Requests: []*http.Request{origin}},
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the cascade to be canceled by the context, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("Expected the cascade to stop with the context")
+	}
+}
+
+func TestNewExponentialCascade(t *testing.T) {
+	var targets []*http.Request
+	for i := 0; i < 4; i++ {
+		req, err := http.NewRequest("GET", "http://localhost", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, req)
+	}
+
+	tiers, err := NewExponentialCascade(targets, 50*time.Millisecond, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}
+	for i, tier := range tiers {
+		if tier.Timeout != expected[i] {
+			t.Fatalf("Expected timeout %v for tier %d, got %v", expected[i], i, tier.Timeout)
+		}
+		if len(tier.Requests) != 1 || tier.Requests[0] != targets[i] {
+			t.Fatalf("Expected tier %d to contain target %d only", i, i)
+		}
+	}
+}
+
+func TestNewExponentialCascade_Limits(t *testing.T) {
+	targets := make([]*http.Request, 100)
+	for i := range targets {
+		targets[i], _ = http.NewRequest("GET", "http://localhost", nil)
+	}
+
+	tiers, err := NewExponentialCascade(targets, time.Second, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tier := range tiers {
+		if tier.Timeout <= 0 || tier.Timeout > maxTierTimeout {
+			t.Fatalf("Expected the timeout of tier %d to be capped, got %v", i, tier.Timeout)
+		}
+	}
+
+	for _, test := range []struct {
+		baseDelay time.Duration
+		factor    float64
+	}{{0, 2}, {-time.Second, 2}, {time.Second, 0.5}, {time.Second, math.NaN()}} {
+		if _, err := NewExponentialCascade(targets, test.baseDelay, test.factor); !errors.Is(err, ErrInvalidCascade) {
+			t.Fatalf("Expected %v and %v to be rejected, got %v", test.baseDelay, test.factor, err)
+		}
+	}
+}
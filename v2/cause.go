@@ -0,0 +1,35 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrLostRace is the cause of the cancellation of the attempts that lost a race,
+// it's available to transports and logs via context.Cause of the request's context
+var ErrLostRace = errors.New("race: lost the race")
+
+// lostTo returns the cause of the cancellation of the losers of a race won by res
+func lostTo(res *http.Response) error {
+	if res.Request == nil {
+		return ErrLostRace
+	}
+	return fmt.Errorf("%w to %s", ErrLostRace, res.Request.URL.Host)
+}
+
+// withCause adds the cause of the cancellation of the context to the error
+func withCause(ctx context.Context, err error) error {
+	cause := context.Cause(ctx)
+	if cause == nil || cause == ctx.Err() || errors.Is(err, cause) {
+		return err
+	}
+	return fmt.Errorf("%w (%w)", err, cause)
+}
+
+// canceled reports whether the attempt was canceled, e.g. because it lost the race,
+// rather than failed. Depending on the transport the cause replaces context.Canceled
+func canceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, ErrLostRace) || errors.Is(err, ErrClosed)
+}
@@ -0,0 +1,70 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type causeTransport struct {
+	causes chan error
+}
+
+func (transport *causeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		transport.causes <- context.Cause(req.Context())
+	}
+	return res, err
+}
+
+func TestLostRaceCause(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowServer.Close()
+
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fastServer.Close()
+
+	slow, err := http.NewRequest("GET", slowServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fast, err := http.NewRequest("GET", fastServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := &causeTransport{causes: make(chan error, 1)}
+	r := NewWithClient(&http.Client{Transport: transport})
+
+	res, err := r.Between(slow, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	u, err := url.Parse(fastServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case cause := <-transport.causes:
+		if !errors.Is(cause, ErrLostRace) || !strings.HasSuffix(cause.Error(), u.Host) {
+			t.Fatalf("Expected the loser to know it lost to %s, got %v", u.Host, cause)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the loser to be canceled")
+	}
+}
@@ -0,0 +1,145 @@
+package race
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+)
+
+// ErrChecksumMismatch is the error of reading the end of a body whose digest isn't the expected one
+var ErrChecksumMismatch = errors.New("race: checksum mismatch")
+
+// Checksum verifies the bodies of the responses, see WithChecksum
+type Checksum struct {
+	// Hash returns a new hash, e.g. sha256.New
+	Hash func() hash.Hash
+	// Expected returns the expected digest of the body of the response, e.g. decoded from a header
+	// of the target or a digest known in advance. A nil digest skips the verification
+	Expected func(res *http.Response) []byte
+	// Encoded verifies the bytes as they were transferred, with their Content-Encoding, e.g. when the
+	// digest of the targets is computed on the compressed file. The request must set Accept-Encoding,
+	// otherwise the transport decompresses gzip before it can be verified.
+	// By default the decoded content is verified, so the targets can disagree about Content-Encoding
+	Encoded bool
+}
+
+// WithChecksum verifies the digests of the bodies while the caller reads them, the read reaching the
+// end of a body fails with ErrChecksumMismatch instead of io.EOF when it doesn't match. The content
+// is decoded on the side to be verified if the Race doesn't decode it, see WithDecoding
+func WithChecksum(checksum Checksum) Option {
+	return func(race *Race) {
+		race.checksum = &checksum
+	}
+}
+
+// decodeAndVerify decodes the body of the response and verifies it against its expected digest
+func (race *Race) decodeAndVerify(res *http.Response) {
+	var expected []byte
+	if race.checksum != nil {
+		expected = race.checksum.Expected(res)
+	}
+	if expected == nil {
+		race.decode(res)
+		return
+	}
+
+	if race.checksum.Encoded {
+		res.Body = newChecksumBody(res.Body, race.checksum.Hash(), expected, nil, nil)
+		race.decode(res)
+		return
+	}
+
+	race.decode(res)
+	// the codings that are left weren't decoded by the Race
+	decoders := race.decoders
+	if decoders == nil {
+		decoders = defaultDecoders()
+	}
+	res.Body = newChecksumBody(res.Body, race.checksum.Hash(), expected, contentCodings(res), decoders)
+}
+
+// checksumBody hashes the body while it's read, the content is decoded on the side
+// before it's hashed when it has codings
+type checksumBody struct {
+	io.ReadCloser
+	hash     hash.Hash
+	expected []byte
+
+	// side receives the bytes decoded in the background, done is closed once they are hashed
+	side *io.PipeWriter
+	done chan error
+
+	verified bool
+	err      error
+}
+
+func newChecksumBody(body io.ReadCloser, h hash.Hash, expected []byte, codings []string, decoders map[string]Decoder) *checksumBody {
+	checked := &checksumBody{ReadCloser: body, hash: h, expected: expected}
+	if len(codings) == 0 {
+		return checked
+	}
+	for _, coding := range codings {
+		if decoders[coding] == nil {
+			checked.err = fmt.Errorf("%w: no decoder of %q", ErrChecksumMismatch, coding)
+			return checked
+		}
+	}
+
+	r, w := io.Pipe()
+	checked.side = w
+	checked.done = make(chan error, 1)
+	go func() {
+		decoded := &decodingBody{raw: r, codings: codings, decoders: decoders}
+		_, err := io.Copy(h, decoded)
+		decoded.Close()
+		checked.done <- err
+	}()
+	return checked
+}
+
+func (body *checksumBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	if body.side != nil {
+		body.side.Write(p[:n])
+	} else {
+		body.hash.Write(p[:n])
+	}
+	if err == io.EOF {
+		return n, body.verify()
+	}
+	return n, err
+}
+
+// verify returns io.EOF if the digest of the body is the expected one
+func (body *checksumBody) verify() error {
+	if body.verified {
+		return body.err
+	}
+	body.verified = true
+
+	if body.side != nil && body.err == nil {
+		body.side.Close()
+		if err := <-body.done; err != nil {
+			body.err = fmt.Errorf("%w: decoding: %v", ErrChecksumMismatch, err)
+		}
+	}
+	if body.err == nil {
+		if sum := body.hash.Sum(nil); !bytes.Equal(sum, body.expected) {
+			body.err = fmt.Errorf("%w: got %x, expected %x", ErrChecksumMismatch, sum, body.expected)
+		}
+	}
+	if body.err == nil {
+		body.err = io.EOF
+	}
+	return body.err
+}
+
+func (body *checksumBody) Close() error {
+	if body.side != nil {
+		body.side.CloseWithError(io.ErrClosedPipe)
+	}
+	return body.ReadCloser.Close()
+}
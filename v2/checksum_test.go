@@ -0,0 +1,71 @@
+package race
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte("content"))
+	gz.Close()
+	encodedSum := sha256.Sum256(compressed.Bytes())
+	decodedSum := sha256.Sum256([]byte("content"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("X-Checksum", hex.EncodeToString(encodedSum[:]))
+		w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	fromHeader := func(res *http.Response) []byte {
+		sum, _ := hex.DecodeString(res.Header.Get("X-Checksum"))
+		return sum
+	}
+	known := func(sum []byte) func(*http.Response) []byte {
+		return func(*http.Response) []byte { return sum }
+	}
+
+	for _, test := range []struct {
+		name     string
+		checksum Checksum
+		decoding bool
+		err      error
+	}{
+		{"decoded on the side", Checksum{Hash: sha256.New, Expected: known(decodedSum[:])}, false, nil},
+		{"decoded by the race", Checksum{Hash: sha256.New, Expected: known(decodedSum[:])}, true, nil},
+		{"encoded", Checksum{Hash: sha256.New, Expected: fromHeader, Encoded: true}, false, nil},
+		{"encoded and decoded by the race", Checksum{Hash: sha256.New, Expected: fromHeader, Encoded: true}, true, nil},
+		{"mismatch", Checksum{Hash: sha256.New, Expected: fromHeader}, false, ErrChecksumMismatch},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			opts := []Option{WithChecksum(test.checksum)}
+			if test.decoding {
+				opts = append(opts, WithDecoding(nil))
+			}
+			r := New(opts...)
+			defer r.Close()
+
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			res, err := r.Between(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			if _, err := ioutil.ReadAll(res.Body); !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
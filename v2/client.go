@@ -0,0 +1,113 @@
+package race
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Client races the same request to several URLs without building the requests by hand,
+// like the methods of http.Client
+type Client struct {
+	// Race makes the races, a new Race with the default client is used if it's nil
+	Race *Race
+}
+
+// Get races GET requests to the URLs
+func (c *Client) Get(urls ...string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req, urls...)
+}
+
+// Head races HEAD requests to the URLs
+func (c *Client) Head(urls ...string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req, urls...)
+}
+
+// Post races POST requests of the body to the URLs, the body is read once and sent by every request.
+// The Race must allow it, see AllowNonIdempotent
+func (c *Client) Post(contentType string, body io.Reader, urls ...string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, "", body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.Do(req, urls...)
+}
+
+// Do sends a copy of the request, with its method, headers and body, to each URL in place of the URL
+// of the request and returns the first answer. The race is canceled with the context of the request
+func (c *Client) Do(req *http.Request, urls ...string) (*http.Response, error) {
+	race := c.Race
+	if race == nil {
+		race = New()
+	}
+
+	reqs, err := urlRequests(req, urls)
+	if err != nil {
+		return nil, err
+	}
+	return race.BetweenContext(req.Context(), reqs...)
+}
+
+// BetweenURLs races requests of the method without a body to the URLs and returns the first answer
+// and the URL that gave it
+func (race *Race) BetweenURLs(ctx context.Context, method string, urls ...string) (*http.Response, string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, "", nil)
+	if err != nil {
+		return nil, "", err
+	}
+	reqs, err := urlRequests(req, urls)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result, err := race.betweenResult(ctx, reqs)
+	if err != nil {
+		return nil, "", err
+	}
+	for i, req := range reqs {
+		if req == result.Request {
+			return result.Response, urls[i], nil
+		}
+	}
+	return result.Response, result.Request.URL.String(), nil
+}
+
+// BetweenURLs races requests of the method without a body to the URLs and returns the first answer
+// and the URL that gave it
+func BetweenURLs(ctx context.Context, method string, urls ...string) (*http.Response, string, error) {
+	return New().BetweenURLs(ctx, method, urls...)
+}
+
+// urlRequests returns a copy of the request, with its method, headers and body, to each URL
+func urlRequests(req *http.Request, urls []string) ([]*http.Request, error) {
+	getBody, err := replayableBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs := make([]*http.Request, len(urls))
+	for i, rawURL := range urls {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		clone := req.Clone(req.Context())
+		clone.URL = u
+		clone.Host = ""
+		if err := withBody(clone, getBody); err != nil {
+			return nil, err
+		}
+		reqs[i] = clone
+	}
+	return reqs, nil
+}
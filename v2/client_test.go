@@ -0,0 +1,76 @@
+package race
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	echo := func(delay time.Duration) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+			w.Write([]byte(r.Method + " " + r.URL.Path + " " + r.Header.Get("Content-Type") + " " + string(body)))
+		}))
+	}
+	slowServer := echo(time.Second)
+	defer slowServer.Close()
+	fastServer := echo(10 * time.Millisecond)
+	defer fastServer.Close()
+
+	client := &Client{}
+	res, err := client.Get(slowServer.URL+"/users", fastServer.URL+"/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "GET /users  " {
+		t.Fatalf("Unexpected response %q", body)
+	}
+
+	client = &Client{Race: New(AllowNonIdempotent())}
+	res, err = client.Post("text/plain", strings.NewReader("hello"), slowServer.URL+"/echo", fastServer.URL+"/echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "POST /echo text/plain hello" {
+		t.Fatalf("Unexpected response %q", body)
+	}
+
+	if _, err := client.Get(); err != ErrNoRequests {
+		t.Fatalf("Expected ErrNoRequests, got %v", err)
+	}
+}
+
+func TestBetweenURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method))
+	}))
+	defer server.Close()
+
+	res, winner, err := BetweenURLs(context.Background(), http.MethodGet, unresolvableDomain, server.URL+"/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if winner != server.URL+"/file" {
+		t.Fatalf("Expected the URL of the server to win, got %q", winner)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	if string(body) != http.MethodGet {
+		t.Fatalf("Unexpected response %q", body)
+	}
+}
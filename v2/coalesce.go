@@ -0,0 +1,162 @@
+package race
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WithCoalescing delays the start of the races of Between by up to the window, identical races
+// started in the meantime or while the race is running share its result, which is useful for
+// extremely hot keys. Races are identical when their requests have the same method, URL and headers.
+// The body of the shared response is buffered and every caller gets its own copy.
+// Only the races of GET and HEAD requests without a body are coalesced
+func WithCoalescing(window time.Duration) Option {
+	return func(race *Race) {
+		race.coalesceWindow = window
+	}
+}
+
+// flight is a race shared by identical calls
+type flight struct {
+	done chan struct{}
+	res  *http.Response
+	body []byte
+	err  error
+}
+
+// coalesceKey returns the key identifying identical races, if they can be coalesced
+func (race *Race) coalesceKey(reqs []*http.Request) (string, bool) {
+	if race.coalesceWindow <= 0 {
+		return "", false
+	}
+	return race.raceKey(reqs)
+}
+
+// raceKey returns the key identifying identical races, if their results can be shared. The URLs are
+// keyed by their resource, so with WithAuthority the races of a resource share it whichever mirrors they use
+func (race *Race) raceKey(reqs []*http.Request) (string, bool) {
+	if len(reqs) == 0 {
+		return "", false
+	}
+
+	keys := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead && req.Method != "" {
+			return "", false
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			return "", false
+		}
+
+		var key strings.Builder
+		key.WriteString(req.Method)
+		key.WriteByte(' ')
+		key.WriteString(race.resourceURL(req.URL).String())
+		key.WriteByte('\n')
+
+		names := make([]string, 0, len(req.Header))
+		for name := range req.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			key.WriteString(name)
+			key.WriteByte(':')
+			key.WriteString(strings.Join(req.Header[name], ","))
+			key.WriteByte('\n')
+		}
+		key.WriteByte('\n')
+		keys = append(keys, key.String())
+	}
+
+	if len(race.authorities) > 0 {
+		// the mirrors of a resource are interchangeable, their order doesn't matter
+		sort.Strings(keys)
+		unique := keys[:1]
+		for _, key := range keys[1:] {
+			if key != unique[len(unique)-1] {
+				unique = append(unique, key)
+			}
+		}
+		keys = unique
+	}
+	return strings.Join(keys, ""), true
+}
+
+// coalesce runs the race once for the identical calls. The shared race is made with a context keeping
+// the values of the context of the call starting it but not its cancellation, so it's only canceled
+// when the Race is closed, and every call waits for it until its own context is done
+func (race *Race) coalesce(ctx context.Context, key string, run func(ctx context.Context) (*http.Response, error)) (*http.Response, error) {
+	race.mu.Lock()
+	if race.flights == nil {
+		race.flights = make(map[string]*flight)
+	}
+	f, ok := race.flights[key]
+	if !ok {
+		f = &flight{done: make(chan struct{})}
+		race.flights[key] = f
+	}
+	race.mu.Unlock()
+
+	if !ok {
+		race.background.Add(1)
+		go func() {
+			defer race.background.Done()
+			race.fly(f, key, detached{ctx}, run)
+		}()
+	}
+
+	select {
+	case <-f.done:
+		return f.response()
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
+
+// fly runs the shared race of the flight after the coalescing window
+func (race *Race) fly(f *flight, key string, ctx context.Context, run func(ctx context.Context) (*http.Response, error)) {
+	timer := time.NewTimer(race.coalesceWindow)
+	select {
+	case <-timer.C:
+		f.res, f.err = run(ctx)
+	case <-race.ctx.Done():
+		timer.Stop()
+		f.err = context.Cause(race.ctx)
+	}
+	if f.err == nil {
+		f.body, f.err = ioutil.ReadAll(f.res.Body)
+		f.res.Body.Close()
+	}
+
+	race.mu.Lock()
+	delete(race.flights, key)
+	race.mu.Unlock()
+	close(f.done)
+}
+
+// detached is a context with the values of its parent, it's never canceled
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+
+// response returns a copy of the shared response
+func (f *flight) response() (*http.Response, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	res := *f.res
+	res.Header = f.res.Header.Clone()
+	res.Body = ioutil.NopCloser(bytes.NewReader(f.body))
+	return &res, nil
+}
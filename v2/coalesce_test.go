@@ -0,0 +1,121 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalescing(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte("hot"))
+	}))
+	defer server.Close()
+
+	r := New(WithCoalescing(100 * time.Millisecond))
+	defer r.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			res, err := r.Between(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer res.Body.Close()
+
+			body, _ := ioutil.ReadAll(res.Body)
+			if string(body) != "hot" {
+				t.Errorf("Unexpected body %q", body)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Fatalf("Expected the races to be coalesced, got %d hits", n)
+	}
+
+	// the others are not identical
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set("Authorization", "Bearer other")
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	req, _ = http.NewRequest("POST", server.URL, strings.NewReader("data"))
+	res, err = r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if n := atomic.LoadInt32(&hits); n != 3 {
+		t.Fatalf("Expected 3 hits, got %d", n)
+	}
+}
+
+func TestCoalescing_Canceled(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("hot"))
+	}))
+	defer server.Close()
+
+	r := New(WithCoalescing(20 * time.Millisecond))
+	defer r.Close()
+
+	// the call starting the shared race gives up, the others still get its result
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		_, err := r.BetweenContext(ctx, req)
+		leader <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	follower := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		res, err := r.BetweenContext(context.Background(), req)
+		if err == nil {
+			body, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			if string(body) != "hot" {
+				err = errors.New("unexpected body " + string(body))
+			}
+		}
+		follower <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the leader to be canceled, got %v", err)
+	}
+	if err := <-follower; err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Fatalf("Expected the races to be coalesced, got %d hits", n)
+	}
+}
@@ -0,0 +1,111 @@
+package race
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Comparator reports whether two bodies are equivalent, it's used by the modes that
+// compare the responses of different targets. It must be safe for concurrent use
+type Comparator func(a, b []byte) bool
+
+// WithComparator sets how the bodies are compared, the default is ExactBytes
+func WithComparator(comparator Comparator) Option {
+	return func(race *Race) {
+		race.comparator = comparator
+	}
+}
+
+func (race *Race) equal(a, b []byte) bool {
+	if race.comparator == nil {
+		return ExactBytes(a, b)
+	}
+	return race.comparator(a, b)
+}
+
+// ExactBytes reports whether the bodies are byte by byte identical
+func ExactBytes(a, b []byte) bool {
+	return bytes.Equal(a, b)
+}
+
+// CanonicalJSON reports whether the bodies are the same JSON value,
+// regardless of the formatting and the order of the keys of objects.
+// Bodies that are not valid JSON are compared byte by byte
+func CanonicalJSON(a, b []byte) bool {
+	va, errA := decodeJSON(a)
+	vb, errB := decodeJSON(b)
+	if errA != nil || errB != nil {
+		return ExactBytes(a, b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// JSONFields returns a comparator that only compares the given fields of JSON objects,
+// nested fields are separated by dots, e.g. "user.id". A field missing from both bodies is equal
+func JSONFields(fields ...string) Comparator {
+	return func(a, b []byte) bool {
+		va, errA := decodeJSON(a)
+		vb, errB := decodeJSON(b)
+		if errA != nil || errB != nil {
+			return false
+		}
+
+		for _, field := range fields {
+			if !reflect.DeepEqual(jsonField(va, field), jsonField(vb, field)) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// IgnoreJSONFields returns a comparator of JSON values that ignores the given fields,
+// e.g. timestamps or request IDs, nested fields are separated by dots
+func IgnoreJSONFields(fields ...string) Comparator {
+	return func(a, b []byte) bool {
+		va, errA := decodeJSON(a)
+		vb, errB := decodeJSON(b)
+		if errA != nil || errB != nil {
+			return false
+		}
+
+		for _, field := range fields {
+			deleteJSONField(va, field)
+			deleteJSONField(vb, field)
+		}
+		return reflect.DeepEqual(va, vb)
+	}
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	err := decoder.Decode(&v)
+	return v, err
+}
+
+func jsonField(v interface{}, field string) interface{} {
+	for _, name := range strings.Split(field, ".") {
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = object[name]
+	}
+	return v
+}
+
+func deleteJSONField(v interface{}, field string) {
+	names := strings.Split(field, ".")
+	parent := v
+	if len(names) > 1 {
+		parent = jsonField(v, strings.Join(names[:len(names)-1], "."))
+	}
+	if object, ok := parent.(map[string]interface{}); ok {
+		delete(object, names[len(names)-1])
+	}
+}
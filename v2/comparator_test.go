@@ -0,0 +1,30 @@
+package race
+
+import "testing"
+
+func TestComparators(t *testing.T) {
+	tests := []struct {
+		name       string
+		comparator Comparator
+		a, b       string
+		equal      bool
+	}{
+		{"exact", ExactBytes, `{"a":1}`, `{"a":1}`, true},
+		{"exact formatting", ExactBytes, `{"a":1}`, `{ "a": 1 }`, false},
+		{"canonical formatting", CanonicalJSON, `{"a":1,"b":[1,2]}`, `{ "b": [1, 2], "a": 1 }`, true},
+		{"canonical different", CanonicalJSON, `{"a":1}`, `{"a":2}`, false},
+		{"canonical invalid", CanonicalJSON, `not json`, `not json`, true},
+		{"fields", JSONFields("id", "user.name"), `{"id":1,"user":{"name":"x"},"at":1}`, `{"id":1,"user":{"name":"x"},"at":2}`, true},
+		{"fields different", JSONFields("user.name"), `{"user":{"name":"x"}}`, `{"user":{"name":"y"}}`, false},
+		{"ignore fields", IgnoreJSONFields("at", "meta.request_id"), `{"id":1,"at":1,"meta":{"request_id":"a"}}`, `{"id":1,"at":2,"meta":{"request_id":"b"}}`, true},
+		{"ignore fields different", IgnoreJSONFields("at"), `{"id":1,"at":1}`, `{"id":2,"at":1}`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if equal := test.comparator([]byte(test.a), []byte(test.b)); equal != test.equal {
+				t.Fatalf("Expected %v, got %v", test.equal, equal)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package race
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Comparison is a loser of a race compared with the winner, the bodies are
+// truncated to the size given to WithComparison and the responses are already closed
+type Comparison struct {
+	Winner     *http.Response
+	WinnerBody []byte
+	// Loser is nil if the loser failed
+	Loser     *http.Response
+	LoserBody []byte
+	LoserErr  error
+	// Incomplete reports that the caller didn't read the body of the winner to its end or
+	// to the size given to WithComparison before closing it, then the bodies aren't compared
+	Incomplete bool
+	// Equal reports whether the loser succeeded and its body is equivalent to the winner's
+	// according to the Comparator of the Race
+	Equal bool
+}
+
+type comparison struct {
+	maxBytes int64
+	fn       func(Comparison)
+}
+
+// WithComparison keeps the losers of every race running instead of canceling them, and calls
+// fn with each of them compared to the winner, reading at most maxBytes of every body.
+// The winner is returned as soon as it arrives and its body is captured while the caller
+// reads it, the comparisons are made in the background once the caller closes the body
+// and all the losers are finished. If the body isn't closed within the budget of
+// WithLoserCompletion, or captureTimeout without one, the comparisons are Incomplete,
+// and they are dropped when the Race is closed
+func WithComparison(maxBytes int64, fn func(Comparison)) Option {
+	return func(race *Race) {
+		race.compare = &comparison{maxBytes: maxBytes, fn: fn}
+	}
+}
+
+type loser struct {
+	res  *http.Response
+	body []byte
+	err  error
+}
+
+// recordLoser reads the body of the loser for the comparison, the caller closes it
+func (c *contest) recordLoser(res *http.Response, err error) {
+	if c.race.compare == nil {
+		return
+	}
+
+	var body []byte
+	if res != nil {
+		body, err = ioutil.ReadAll(io.LimitReader(res.Body, c.race.compare.maxBytes))
+		if err != nil {
+			res = nil
+		}
+	}
+
+	c.mu.Lock()
+	c.losers = append(c.losers, loser{res: res, body: body, err: err})
+	c.mu.Unlock()
+}
+
+// captureTimeout is how long the body of a captured winner is waited for without a loser budget
+const captureTimeout = time.Minute
+
+// captureWinner makes the body of the winner keep up to maxBytes of what the caller reads
+func (c *contest) captureWinner(res *http.Response, maxBytes int64) *http.Response {
+	c.captured = make(chan capture, 1)
+	res.Body = &captureBody{
+		ReadCloser: res.Body,
+		remaining:  maxBytes,
+		captured:   c.captured,
+	}
+	return res
+}
+
+// capturedWinner waits for the caller to close the body of the winner, for up to the loser budget
+// or captureTimeout. The capture is incomplete if it isn't closed in time, ok is false if the Race is closed
+func (c *contest) capturedWinner() (captured capture, ok bool) {
+	wait := c.race.loserBudget
+	if wait <= 0 {
+		wait = captureTimeout
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case captured = <-c.captured:
+	case <-timer.C:
+	case <-c.race.ctx.Done():
+		return capture{}, false
+	}
+	return captured, true
+}
+
+func (c *contest) reportComparisons() {
+	c.mu.Lock()
+	winner := c.winner
+	c.mu.Unlock()
+	if winner == nil {
+		return
+	}
+
+	captured, ok := c.capturedWinner()
+	if !ok {
+		return
+	}
+
+	for _, l := range c.losers {
+		c.race.compare.fn(Comparison{
+			Winner:     winner,
+			WinnerBody: captured.body,
+			Loser:      l.res,
+			LoserBody:  l.body,
+			LoserErr:   l.err,
+			Incomplete: !captured.complete,
+			Equal:      l.err == nil && captured.complete && c.race.equal(captured.body, l.body),
+		})
+	}
+}
+
+// capture is what the caller read of a body
+type capture struct {
+	body []byte
+	// complete reports whether the body was read to its end or to the limit
+	complete bool
+}
+
+type captureBody struct {
+	io.ReadCloser
+	buf       bytes.Buffer
+	remaining int64
+	eof       bool
+	once      sync.Once
+	captured  chan capture
+}
+
+func (body *captureBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	if keep := int64(n); keep > 0 && body.remaining > 0 {
+		if keep > body.remaining {
+			keep = body.remaining
+		}
+		body.buf.Write(p[:keep])
+		body.remaining -= keep
+	}
+	if err == io.EOF {
+		body.eof = true
+	}
+	return n, err
+}
+
+func (body *captureBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(func() {
+		body.captured <- capture{body: body.buf.Bytes(), complete: body.eof || body.remaining <= 0}
+	})
+	return err
+}
@@ -0,0 +1,126 @@
+package race
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newComparedServers(t *testing.T) (*httptest.Server, *httptest.Server, []*http.Request) {
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fast"))
+	}))
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("slow"))
+	}))
+
+	var reqs []*http.Request
+	for _, url := range []string{fastServer.URL, slowServer.URL} {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reqs = append(reqs, req)
+	}
+
+	return fastServer, slowServer, reqs
+}
+
+func TestComparison(t *testing.T) {
+	fastServer, slowServer, reqs := newComparedServers(t)
+	defer fastServer.Close()
+	defer slowServer.Close()
+
+	comparisons := make(chan Comparison, 1)
+	r := New(WithComparison(1024, func(c Comparison) {
+		comparisons <- c
+	}))
+	defer r.Close()
+
+	start := time.Now()
+	res, err := r.Between(reqs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 80*time.Millisecond {
+		t.Fatalf("Expected the winner without waiting for the loser, took %v", elapsed)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "fast" {
+		t.Fatalf("Expected the fast server to win, got %q", body)
+	}
+
+	select {
+	case c := <-comparisons:
+		if string(c.WinnerBody) != "fast" || string(c.LoserBody) != "slow" || c.LoserErr != nil || c.Equal {
+			t.Fatalf("Unexpected comparison %+v", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the loser to be compared with the winner")
+	}
+}
+
+func TestComparison_Close(t *testing.T) {
+	fastServer, slowServer, reqs := newComparedServers(t)
+	defer fastServer.Close()
+	defer slowServer.Close()
+
+	compared := false
+	r := New(WithComparison(1024, func(c Comparison) {
+		compared = true
+	}))
+
+	// the body of the winner is never closed, so only Close ends the comparison
+	if _, err := r.Between(reqs...); err != nil {
+		t.Fatal(err)
+	}
+
+	r.Close()
+	if compared {
+		t.Fatal("Expected the comparison to be dropped")
+	}
+}
+
+func TestComparison_Incomplete(t *testing.T) {
+	fastServer, slowServer, reqs := newComparedServers(t)
+	defer fastServer.Close()
+	defer slowServer.Close()
+
+	comparisons := make(chan Comparison, 2)
+	r := New(WithComparison(1024, func(c Comparison) {
+		comparisons <- c
+	}), WithLoserCompletion(300*time.Millisecond))
+	defer r.Close()
+
+	// the body is closed before it's read
+	res, err := r.Between(reqs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	// the body is never closed, the comparison ends with the loser budget
+	if _, err := r.Between(reqs...); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case c := <-comparisons:
+			if !c.Incomplete || c.Equal || string(c.LoserBody) != "slow" {
+				t.Fatalf("Expected an incomplete comparison, got %+v", c)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the loser to be compared with the winner")
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package race
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+)
+
+// DisagreementError is the error of Consensus when too few targets returned equivalent bodies,
+// errors.Is reports it as ErrMismatch
+type DisagreementError struct {
+	// Quorum is the number of equivalent bodies that were needed
+	Quorum int
+	// Groups are the sizes of the groups of equivalent bodies, the largest first
+	Groups []int
+	// Failed is the number of attempts that failed
+	Failed int
+}
+
+func (e *DisagreementError) Error() string {
+	return fmt.Sprintf("race: no %d targets agreed, the bodies were grouped by %v and %d attempts failed", e.Quorum, e.Groups, e.Failed)
+}
+
+// Is makes errors.Is(err, ErrMismatch) true
+func (e *DisagreementError) Is(target error) bool {
+	return target == ErrMismatch
+}
+
+// Consensus makes the requests simultaneously and returns a response only when the bodies of k of them
+// are equivalent according to the Comparator of the Race, e.g. when reading eventually consistent replicas
+// whose fastest answer may be stale. The others are canceled once k agree. It fails with a *DisagreementError
+// as soon as k can't agree anymore, or like a failed race if all of them failed. The body of the response is buffered
+func (race *Race) Consensus(ctx context.Context, k int, reqs ...*http.Request) (*http.Response, error) {
+	reqs = race.order(reqs)
+	if len(reqs) == 0 {
+		return nil, ErrNoRequests
+	}
+	if k < 1 || k > len(reqs) {
+		return nil, ErrInvalidN
+	}
+	if err := race.checkIdempotent(reqs); err != nil {
+		return nil, err
+	}
+
+	c := race.newContestContext(ctx, race.client.Timeout)
+	defer c.finish()
+
+	c.startAll(reqs)
+
+	// groups holds the responses of equivalent bodies, the first one of a group represents it
+	var groups [][]bufferedResponse
+	errs := make([]error, len(reqs))
+	failures := 0
+	for answered := 0; answered < len(reqs); answered++ {
+		o := <-c.results
+		if o.err == nil {
+			body, err := ioutil.ReadAll(o.res.Body)
+			o.res.Body.Close()
+			if err != nil {
+				o.err = err
+			} else {
+				o.res.Body = ioutil.NopCloser(bytes.NewReader(body))
+				read := bufferedResponse{index: o.index, res: o.res, body: body}
+				if group := race.agree(&groups, read); len(group) >= k {
+					return c.win(group[0].index, reqs[group[0].index], group[0].res)
+				}
+			}
+		}
+		if o.err != nil {
+			errs[o.index] = o.err
+			failures++
+		}
+
+		largest := 0
+		for _, group := range groups {
+			if len(group) > largest {
+				largest = len(group)
+			}
+		}
+		if largest+len(reqs)-answered-1 < k {
+			break
+		}
+	}
+
+	if len(groups) == 0 {
+		return nil, failedAttempts(reqs, errs)
+	}
+	sizes := make([]int, len(groups))
+	for i, group := range groups {
+		sizes[i] = len(group)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return nil, &DisagreementError{Quorum: k, Groups: sizes, Failed: failures}
+}
+
+// Consensus makes the requests simultaneously and returns a response only when the bodies of k of them are equivalent
+func Consensus(ctx context.Context, k int, reqs ...*http.Request) (*http.Response, error) {
+	return New().Consensus(ctx, k, reqs...)
+}
+
+// agree adds the read to the group of the equivalent bodies and returns the group
+func (race *Race) agree(groups *[][]bufferedResponse, read bufferedResponse) []bufferedResponse {
+	for i, group := range *groups {
+		if race.equal(group[0].body, read.body) {
+			(*groups)[i] = append(group, read)
+			return (*groups)[i]
+		}
+	}
+	*groups = append(*groups, []bufferedResponse{read})
+	return (*groups)[len(*groups)-1]
+}
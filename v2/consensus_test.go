@@ -0,0 +1,56 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConsensus(t *testing.T) {
+	body := func(s string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(s))
+		}))
+	}
+	stale := body("v1")
+	defer stale.Close()
+	fresh1 := body("v2")
+	defer fresh1.Close()
+	fresh2 := body("v2")
+	defer fresh2.Close()
+
+	requests := func(servers ...*httptest.Server) []*http.Request {
+		reqs := make([]*http.Request, len(servers))
+		for i, server := range servers {
+			reqs[i], _ = http.NewRequest("GET", server.URL, nil)
+		}
+		return reqs
+	}
+
+	res, err := Consensus(context.Background(), 2, requests(stale, fresh1, fresh2)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(got) != "v2" {
+		t.Fatalf("Expected the agreed body, got %q", got)
+	}
+
+	_, err = Consensus(context.Background(), 2, requests(stale, fresh1)...)
+	var disagreement *DisagreementError
+	if !errors.As(err, &disagreement) || !errors.Is(err, ErrMismatch) {
+		t.Fatalf("Expected a disagreement, got %v", err)
+	}
+	if disagreement.Quorum != 2 || len(disagreement.Groups) != 2 || disagreement.Failed != 0 {
+		t.Fatalf("Expected 2 groups of 1 body, got %+v", disagreement)
+	}
+
+	failing, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	if _, err := Consensus(context.Background(), 1, failing); err == nil || errors.Is(err, ErrMismatch) {
+		t.Fatalf("Expected the race to fail, got %v", err)
+	}
+}
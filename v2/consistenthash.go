@@ -0,0 +1,56 @@
+package race
+
+import (
+	"hash/fnv"
+	"net/http"
+	"sort"
+)
+
+// ConsistentHash returns a strategy that shards the races by a key, e.g. a user ID, extracted
+// from the first target by the given function: the primary is the target the key hashes to and
+// the hedges, at most the given number, are the next ones for that key. Adding or removing a target
+// only moves the keys of that target. Rendezvous hashing is used and targets are identified by their host.
+// Races with an empty key keep the given order and negative hedges are treated as none
+func ConsistentHash(key func(target *http.Request) string, hedges int) Strategy {
+	if hedges < 0 {
+		hedges = 0
+	}
+	return &consistentHash{key: key, hedges: hedges}
+}
+
+type consistentHash struct {
+	key    func(target *http.Request) string
+	hedges int
+}
+
+func (ch *consistentHash) Order(targets []*http.Request) []*http.Request {
+	ordered := make([]*http.Request, len(targets))
+	copy(ordered, targets)
+	if len(ordered) == 0 {
+		return ordered
+	}
+
+	if key := ch.key(ordered[0]); key != "" {
+		weights := make(map[*http.Request]uint64, len(ordered))
+		for _, target := range ordered {
+			weights[target] = rendezvous(key, target.URL.Host)
+		}
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return weights[ordered[i]] > weights[ordered[j]]
+		})
+	}
+
+	if len(ordered) > ch.hedges+1 {
+		ordered = ordered[:ch.hedges+1]
+	}
+	return ordered
+}
+
+// rendezvous is the weight of the host for the key
+func rendezvous(key, host string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(host))
+	return h.Sum64()
+}
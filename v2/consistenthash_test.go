@@ -0,0 +1,74 @@
+package race
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestConsistentHash(t *testing.T) {
+	shard := func(target *http.Request) string { return target.Header.Get("X-Shard") }
+	strategy := ConsistentHash(shard, 1)
+
+	targets := func(key string, hosts ...string) []*http.Request {
+		var reqs []*http.Request
+		for _, host := range hosts {
+			req, _ := http.NewRequest("GET", "http://"+host, nil)
+			req.Header.Set("X-Shard", key)
+			reqs = append(reqs, req)
+		}
+		return reqs
+	}
+
+	moved := 0
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint(i)
+		ordered := strategy.Order(targets(key, "a", "b", "c", "d"))
+		if len(ordered) != 2 || ordered[0].URL.Host == ordered[1].URL.Host {
+			t.Fatalf("Expected a primary and a hedge, got %d", len(ordered))
+		}
+
+		again := strategy.Order(targets(key, "d", "c", "b", "a"))
+		if again[0].URL.Host != ordered[0].URL.Host || again[1].URL.Host != ordered[1].URL.Host {
+			t.Fatal("Expected the order not to depend on the given order")
+		}
+
+		// removing a target only moves its keys
+		without := strategy.Order(targets(key, "a", "b", "c"))
+		if ordered[0].URL.Host != "d" && without[0].URL.Host != ordered[0].URL.Host {
+			t.Fatalf("Expected key %s to stay on %s", key, ordered[0].URL.Host)
+		}
+		if ordered[0].URL.Host == "d" {
+			moved++
+		}
+	}
+	if moved == 0 || moved == 100 {
+		t.Fatalf("Expected the keys to be spread, %d on one target", moved)
+	}
+
+	unsharded := targets("", "a", "b", "c")
+	if ordered := strategy.Order(unsharded); ordered[0] != unsharded[0] || len(ordered) != 2 {
+		t.Fatal("Expected the given order without a key")
+	}
+}
+
+func TestConsistentHash_Hedges(t *testing.T) {
+	key := func(target *http.Request) string { return "key" }
+	var targets []*http.Request
+	for _, host := range []string{"a", "b"} {
+		req, _ := http.NewRequest("GET", "http://"+host, nil)
+		targets = append(targets, req)
+	}
+
+	for _, hedges := range []int{-2, -1, 0} {
+		if n := len(ConsistentHash(key, hedges).Order(targets)); n != 1 {
+			t.Fatalf("Expected the primary only for %d hedges, got %d", hedges, n)
+		}
+	}
+	if n := len(ConsistentHash(key, 5).Order(targets)); n != 2 {
+		t.Fatalf("Expected all the targets, got %d", n)
+	}
+	if n := len(ConsistentHash(key, -1).Order(nil)); n != 0 {
+		t.Fatalf("Expected no targets, got %d", n)
+	}
+}
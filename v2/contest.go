@@ -0,0 +1,387 @@
+package race
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// outcome is the result of an attempt of a race
+type outcome struct {
+	// index is the position of the request in the race
+	index int
+	res   *http.Response
+	// body is the body of the response read by the attempt if the contest is buffered
+	body []byte
+	err  error
+}
+
+// contest is the state shared by the attempts of a single race
+type contest struct {
+	race     *Race
+	id       string
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	results  chan outcome
+	done     chan struct{}
+	attempts sync.WaitGroup
+
+	mu       sync.Mutex
+	versions map[string]string
+	winner   *http.Response
+	captured chan capture
+	losers   []loser
+	// failed are the failures of the attempts by index until the winner reports them, for WithLoserHeaders
+	failed   map[int]LoserHeaders
+	reported bool
+
+	started  time.Time
+	elapsed  time.Duration
+	won      *http.Response
+	timeline []AttemptRecord
+	hints    map[*http.Response][]http.Header
+
+	// winnerIndex, wonAfter and failures describe the winner for its Result
+	winnerIndex int
+	wonAfter    time.Duration
+	failures    int
+
+	// targets are the started requests, tier is the tier being started
+	// and the first primaries of them are the primaries
+	targets   []*http.Request
+	tier      int
+	primaries int
+	verifier  *http.Request
+	verified  *http.Response
+
+	// buffered makes the attempts read the bodies of their responses, see DoubleRead
+	buffered bool
+
+	// options are the Attempts of the requests given to Run
+	options map[*http.Request]*Attempt
+
+	// cancels cancel the attempts by index, the kept ones outlive the race
+	// until their body is closed, the contest is canceled after them
+	cancels  map[int]context.CancelCauseFunc
+	kept     map[int]bool
+	open     int
+	finished bool
+}
+
+// newContest returns the contest of a new race, it's canceled after the timeout if it's not zero
+func (race *Race) newContest(timeout time.Duration) *contest {
+	return race.newContestContext(context.Background(), timeout)
+}
+
+// newContestContext returns the contest of a new race canceled when the context is done or the Race is closed
+func (race *Race) newContestContext(parent context.Context, timeout time.Duration) *contest {
+	watch := parent != context.Background()
+	if !watch {
+		parent = race.ctx
+	}
+
+	id := newID()
+	ctx, cancel := createContext(context.WithValue(parent, idKey{}, id), timeout)
+	if watch {
+		go func() {
+			select {
+			case <-race.ctx.Done():
+				cancel(context.Cause(race.ctx))
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return &contest{
+		race:      race,
+		id:        id,
+		primaries: 1,
+		ctx:       ctx,
+		cancel:    cancel,
+		results:   make(chan outcome),
+		done:      make(chan struct{}),
+		started:   time.Now(),
+		cancels:   make(map[int]context.CancelCauseFunc),
+		kept:      make(map[int]bool),
+	}
+}
+
+// start makes the request concurrently,
+// index is the position of the request in the race, the first one is the primary
+func (c *contest) start(index int, req *http.Request) {
+	c.targets = append(c.targets, req)
+	ctx, cancel := c.attemptContext(req)
+	p := priority{tier: c.tier, index: index, hedge: index >= c.primaries}
+	ctx = withPriority(ctx, p)
+	c.mu.Lock()
+	c.cancels[index] = cancel
+	c.mu.Unlock()
+
+	// the attempts take the budgets in the order they start
+	spent := c.race.hedgeBudget.spend(p.hedge)
+	if spent == nil {
+		spent = spendAttempt(ctx)
+	}
+	c.attempts.Add(1)
+	go func() {
+		defer c.attempts.Done()
+		c.makeRequest(index, c.race.withID(req.WithContext(ctx)), spent)
+	}()
+}
+
+// startAll starts all the requests at once for the calls collecting several of their outcomes,
+// e.g. FirstN or All. Unlike run it doesn't apply the brownout, the experiment or the hedge stats,
+// which are about the hedges of the races with one winner while these calls need all their requests
+func (c *contest) startAll(reqs []*http.Request) {
+	for i, req := range reqs {
+		c.start(i, req)
+	}
+}
+
+// keep makes the response of the attempt outlive the race, the attempt is canceled when its body is closed
+func (c *contest) keep(index int, res *http.Response) *http.Response {
+	c.mu.Lock()
+	c.kept[index] = true
+	c.open++
+	cancel := c.cancels[index]
+	c.mu.Unlock()
+
+	res.Body = &releaseOnClose{ReadCloser: res.Body, release: func() {
+		cancel(nil)
+
+		c.mu.Lock()
+		c.open--
+		last := c.open == 0 && c.finished
+		c.mu.Unlock()
+		if last {
+			c.cancel(nil)
+		}
+	}}
+	return res
+}
+
+// cancelAttempts cancels the attempts except the kept ones
+func (c *contest) cancelAttempts(cause error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for index, cancel := range c.cancels {
+		if !c.kept[index] {
+			cancel(cause)
+		}
+	}
+}
+
+// release cancels the contest once the race is over and the kept responses are closed
+func (c *contest) release() {
+	c.mu.Lock()
+	c.finished = true
+	last := c.open == 0
+	c.mu.Unlock()
+	if last {
+		c.cancel(nil)
+	}
+}
+
+// win must be called with the winner of the race, its position in the race and its target.
+// The losers are canceled unless they are compared with the winner or allowed to complete,
+// then the winner is processed
+func (c *contest) win(index int, target *http.Request, res *http.Response) (*http.Response, error) {
+	c.mu.Lock()
+	c.won = res
+	c.mu.Unlock()
+	c.winnerIndex = index
+	c.wonAfter = time.Since(c.started)
+
+	if observer, ok := c.race.strategy.(WinnerObserver); ok {
+		observer.Won(target)
+	}
+	c.reportHints(target, res)
+	c.reportFailedLosers()
+
+	res = c.keep(index, res)
+	if c.race.compare == nil {
+		if c.race.loserBudget <= 0 {
+			c.cancelAttempts(lostTo(res))
+		}
+		res = c.sampleVerification(target, res)
+	} else {
+		c.mu.Lock()
+		c.winner = res
+		c.mu.Unlock()
+		res = c.captureWinner(res, c.race.compare.maxBytes)
+	}
+
+	return c.race.process(res, index)
+}
+
+// finish must be called when the race is over,
+// the responses of the losers that arrive later are closed
+func (c *contest) finish() {
+	c.mu.Lock()
+	c.elapsed = time.Since(c.started)
+	c.mu.Unlock()
+	close(c.done)
+
+	if c.race.compare == nil && c.race.loserBudget <= 0 {
+		c.cancelAttempts(nil)
+		if c.race.skewHook == nil && !c.race.recording() && c.verifier == nil {
+			c.release()
+			return
+		}
+	}
+
+	// wait for the losers in the background
+	c.race.background.Add(1)
+	go func() {
+		defer c.race.background.Done()
+		defer c.cancelLosersAfterBudget()()
+
+		c.attempts.Wait()
+		c.reportSkew()
+		c.reportComparisons()
+		c.verify()
+		c.reportRecord()
+		c.cancelAttempts(nil)
+		c.release()
+	}()
+}
+
+// makeRequest sends the outcome of the request to results, the request isn't made if spent is
+// the error of its budget. If the race is already done the response of the loser is closed
+func (c *contest) makeRequest(index int, req *http.Request, spent error) {
+	req, recordHints := c.traceHints(req)
+	start := time.Now()
+	var res *http.Response
+	err := spent
+	if err == nil {
+		res, err = c.race.doAttempt(req)
+	}
+	var body []byte
+	if err == nil && c.buffered {
+		if body, err = ioutil.ReadAll(res.Body); err != nil {
+			res.Body.Close()
+			res = nil
+		} else {
+			res.Body.Close()
+			res.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+	}
+	elapsed := time.Since(start)
+	recordHints(res)
+	c.recordAttempt(index, req, start, res, err)
+	if err != nil {
+		c.recordLoser(nil, err)
+	} else {
+		c.recordVersion(req.URL.Host, res)
+	}
+
+	recorded := err != nil && c.recordFailure(index, req, elapsed, err)
+
+	select {
+	case c.results <- outcome{index: index, res: res, body: body, err: err}:
+	case <-c.done:
+		if !recorded || c.unreported(index) {
+			c.reportLoserHeaders(req, elapsed, res, err)
+		}
+		if res != nil {
+			c.recordLoser(res, nil)
+			res.Body.Close()
+		}
+	}
+}
+
+// run starts the tiers one after another and returns the first answer,
+// the next tier starts when the timeout of the current one elapses or all the started requests failed
+func (c *contest) run(tiers []Tier) (*http.Response, error) {
+	var reqs []*http.Request
+	for _, tier := range tiers {
+		reqs = append(reqs, tier.Requests...)
+	}
+	if err := c.race.checkIdempotent(reqs); err != nil {
+		return nil, err
+	}
+
+	tiers = c.race.brownout.reduce(tiers)
+	tiers, control := c.race.experiment.sample(tiers)
+
+	res, err := c.runTiers(tiers)
+	c.race.experiment.observe(control, time.Since(c.started), err)
+	if err == nil {
+		c.race.hedgeStats.observe(len(c.targets) > c.primaries, c.winnerIndex < c.primaries, c.wonAfter)
+	}
+	return res, err
+}
+
+func (c *contest) runTiers(tiers []Tier) (*http.Response, error) {
+	var reqs []*http.Request
+	for _, tier := range tiers {
+		reqs = append(reqs, tier.Requests...)
+	}
+	if len(reqs) == 0 {
+		return nil, ErrNoRequests
+	}
+
+	if len(tiers) > 1 {
+		c.primaries = len(tiers[0].Requests)
+	}
+
+	errs := make([]error, len(reqs))
+	failures := 0
+	started := 0
+	for i, tier := range tiers {
+		c.tier = i
+		for range tier.Requests {
+			c.start(started, reqs[started])
+			started++
+		}
+
+		// there is no deadline for the last tier
+		var timer *time.Timer
+		var deadline <-chan time.Time
+		if i < len(tiers)-1 {
+			timer = time.NewTimer(tier.Timeout)
+			deadline = timer.C
+		}
+
+	WAIT:
+		for failures < started {
+			select {
+			case o := <-c.results:
+				if o.err == nil {
+					if timer != nil {
+						timer.Stop()
+					}
+					o, settled := c.settle(o, errs)
+					c.failures = failures + settled
+					return c.win(o.index, reqs[o.index], o.res)
+				}
+				errs[o.index] = o.err
+				failures++
+			case <-deadline:
+				break WAIT
+			}
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+
+	// all requests failed
+	return nil, failedAttempts(reqs, errs)
+}
+
+func createContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelCauseFunc) {
+	if timeout > 0 {
+		ctx, stop := context.WithTimeout(parent, timeout)
+		ctx, cancel := context.WithCancelCause(ctx)
+		return ctx, func(cause error) {
+			cancel(cause)
+			stop()
+		}
+	}
+
+	return context.WithCancelCause(parent)
+}
@@ -0,0 +1,52 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrFirstByteTimeout is the cause of the cancellation of an attempt whose response
+// didn't start within the deadline given to WithFirstByteTimeouts
+var ErrFirstByteTimeout = errors.New("race: first byte timeout")
+
+// WithFirstByteTimeouts gives the primaries (the first target of the race, or the first tier
+// when others follow it) and the hedges different deadlines for the response headers to arrive,
+// e.g. the primary gets 2s and the hedges only 500ms, so late hedges don't extend the latency
+// of the race when they turn out slow too. Reading the body is not limited. Zero means no deadline
+func WithFirstByteTimeouts(primary, hedge time.Duration) Option {
+	return func(race *Race) {
+		race.primaryTimeout = primary
+		race.hedgeTimeout = hedge
+	}
+}
+
+// doWithDeadline makes the attempt with the first byte deadline of its role in the race
+func (race *Race) doWithDeadline(req *http.Request) (*http.Response, error) {
+	timeout := race.primaryTimeout
+	if priorityOf(req.Context()).hedge {
+		timeout = race.hedgeTimeout
+	}
+	if timeout <= 0 {
+		return race.do(req)
+	}
+
+	ctx, cancel := context.WithCancelCause(req.Context())
+	timer := time.AfterFunc(timeout, func() { cancel(ErrFirstByteTimeout) })
+
+	res, err := race.do(req.WithContext(ctx))
+	if !timer.Stop() && err == nil {
+		// the deadline elapsed as the response arrived, its body would be canceled mid-read
+		res.Body.Close()
+		err = withCause(ctx, context.Canceled)
+	}
+	if err != nil {
+		cancel(nil)
+		return nil, err
+	}
+
+	// the context must live until the body is read
+	res.Body = &releaseOnClose{ReadCloser: res.Body, release: func() { cancel(nil) }}
+	return res, nil
+}
@@ -0,0 +1,94 @@
+package race
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+func TestFirstByteTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+			w.Write([]byte("hello"))
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	primary, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hedge, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the hedge gives up long before the primary answers
+	r := New(WithFirstByteTimeouts(time.Second, 50*time.Millisecond))
+	res, err := r.FirstThenStart(primary, 10*time.Millisecond, hedge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "hello" {
+		t.Fatalf("Expected the body of the primary, got %q", body)
+	}
+
+	r = New(WithFirstByteTimeouts(50*time.Millisecond, 50*time.Millisecond))
+	start := time.Now()
+	_, err = r.Between(primary, hedge)
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Fatalf("Expected the race to fail after the deadlines, took %v", elapsed)
+	}
+	if Classify(err) != FailureTimeout {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+	multiError := err.(*multierror.Error)
+	if !errors.Is(multiError.Errors[0], ErrFirstByteTimeout) {
+		t.Fatalf("Expected ErrFirstByteTimeout, got %v", multiError.Errors[0])
+	}
+}
+
+func TestFirstByteTimeouts_Primaries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	failing, err := http.NewRequest("GET", "http://127.0.0.1:1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slow, err := http.NewRequest("GET", server.URL+"/slow", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the second primary gets the deadline of the primaries, not the one of the hedges
+	r := New(WithFirstByteTimeouts(time.Second, 10*time.Millisecond))
+	res, err := r.PrimariesThenStart([]*http.Request{failing, slow}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "/slow" {
+		t.Fatalf("Expected the second primary to win, got %q", body)
+	}
+}
@@ -0,0 +1,242 @@
+package race
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// ErrDecompressionBomb is the error of reading a decoded body that expands beyond the ratio of WithMaxDecompressionRatio
+var ErrDecompressionBomb = errors.New("race: decompression ratio exceeded")
+
+// decompressionAllowance is how many decoded bytes are read before the ratio is enforced,
+// small bodies of repeated bytes legitimately compress very well
+const decompressionAllowance = 1 << 20
+
+// decompressionPreread is how many decoded bytes an attempt checks before it can win
+const decompressionPreread = 2 * decompressionAllowance
+
+// gzipKey marks the requests asking for gzip on behalf of the transport
+type gzipKey struct{}
+
+// Decoder decodes a body of a content coding, e.g. brotli
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+// WithDecoding decodes the bodies of the responses whose Content-Encoding has a decoder, so the
+// caller, and the features comparing the bodies, see the same canonical bytes whichever target
+// responded, e.g. when mirrors serve gzip and identity. gzip and deflate are decoded by default,
+// the given decoders are added by content coding, e.g. "br". Responses with an unknown coding are kept
+func WithDecoding(decoders map[string]Decoder) Option {
+	return func(race *Race) {
+		race.decoders = defaultDecoders()
+		for coding, decoder := range decoders {
+			race.decoders[strings.ToLower(coding)] = decoder
+		}
+	}
+}
+
+// defaultDecoders returns the decoders of gzip and deflate
+func defaultDecoders() map[string]Decoder {
+	return map[string]Decoder{
+		"gzip": func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		"x-gzip": func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		"deflate": zlib.NewReader,
+	}
+}
+
+// WithMaxDecompressionRatio limits how much the bodies decoded by WithDecoding can expand, so racing
+// unknown mirrors doesn't expose the caller to zip bombs. Once the decoded body of an attempt is larger
+// than 1MiB and than ratio times the bytes received it fails with ErrDecompressionBomb: the first 2MiB
+// are decoded by the attempt, which fails and lets the race go on, the rest by the reads of the caller.
+// The requests without Accept-Encoding ask for gzip themselves, so the bodies the transport would
+// decompress are limited too. The ratio of a Target overrides it, see Target.MaxDecompressionRatio
+func WithMaxDecompressionRatio(ratio float64) Option {
+	return func(race *Race) {
+		race.maxDecompressionRatio = ratio
+	}
+}
+
+// decompressionRatio returns the maximum decompression ratio of the request, zero means no limit
+func (race *Race) decompressionRatio(req *http.Request) float64 {
+	if target, ok := TargetOf(req); ok && target.MaxDecompressionRatio > 0 {
+		return target.MaxDecompressionRatio
+	}
+	return race.maxDecompressionRatio
+}
+
+// acceptGzip returns a copy of the request asking for gzip when its decompression ratio is limited,
+// otherwise the transport would ask for it and decompress the body before the ratio is enforced
+func (race *Race) acceptGzip(req *http.Request) *http.Request {
+	if race.decompressionRatio(req) <= 0 || req.Header.Get("Accept-Encoding") != "" || req.Header.Get("Range") != "" {
+		return req
+	}
+
+	clone := req.Clone(context.WithValue(req.Context(), gzipKey{}, true))
+	clone.Header.Set("Accept-Encoding", "gzip")
+	return clone
+}
+
+// decoding returns the codings of the response and their decoders,
+// nil if the response isn't encoded or has a coding without a decoder
+func (race *Race) decoding(res *http.Response) ([]string, map[string]Decoder) {
+	decoders := race.decoders
+	if decoders == nil && res.Request != nil && res.Request.Context().Value(gzipKey{}) != nil {
+		decoders = defaultDecoders()
+	}
+	if decoders == nil {
+		return nil, nil
+	}
+
+	codings := contentCodings(res)
+	if len(codings) == 0 {
+		return nil, nil
+	}
+	for _, coding := range codings {
+		if decoders[coding] == nil {
+			return nil, nil
+		}
+	}
+	return codings, decoders
+}
+
+// checkDecompression decodes the first bytes of the body of the response on the side and fails
+// with ErrDecompressionBomb if they exceed the ratio of the request, the body is closed on error
+func (race *Race) checkDecompression(req *http.Request, res *http.Response) error {
+	ratio := race.decompressionRatio(req)
+	if ratio <= 0 {
+		return nil
+	}
+	codings, decoders := race.decoding(res)
+	if codings == nil {
+		return nil
+	}
+
+	var received bytes.Buffer
+	probe := &decodingBody{
+		raw:      ioutil.NopCloser(io.TeeReader(res.Body, &received)),
+		codings:  codings,
+		decoders: decoders,
+		maxRatio: ratio,
+		host:     req.URL.Host,
+	}
+	_, err := io.CopyN(ioutil.Discard, probe, decompressionPreread)
+	probe.Close()
+	res.Body = &prereadBody{Reader: io.MultiReader(&received, res.Body), Closer: res.Body}
+	if errors.Is(err, ErrDecompressionBomb) {
+		res.Body.Close()
+		return err
+	}
+	return nil
+}
+
+// prereadBody is a body whose first bytes were read in advance
+type prereadBody struct {
+	io.Reader
+	io.Closer
+}
+
+// decode replaces the body of the response with the decoded one
+func (race *Race) decode(res *http.Response) {
+	codings, decoders := race.decoding(res)
+	if codings == nil {
+		return
+	}
+
+	body := &decodingBody{raw: res.Body, codings: codings, decoders: decoders}
+	if res.Request != nil {
+		body.host = res.Request.URL.Host
+		body.maxRatio = race.decompressionRatio(res.Request)
+	}
+	res.Body = body
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+}
+
+// contentCodings returns the content codings of the response in the order they were applied
+func contentCodings(res *http.Response) []string {
+	var codings []string
+	for _, header := range res.Header.Values("Content-Encoding") {
+		for _, coding := range strings.Split(header, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "" && coding != "identity" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+	return codings
+}
+
+// decodingBody decodes the body when it's first read, the codings are in the order they were applied
+type decodingBody struct {
+	raw      io.ReadCloser
+	codings  []string
+	decoders map[string]Decoder
+
+	// maxRatio limits the decoded bytes to a ratio of the received ones, zero means no limit
+	maxRatio float64
+	host     string
+	received int64
+	decoded  int64
+
+	r       io.Reader
+	closers []io.Closer
+	err     error
+}
+
+func (body *decodingBody) Read(p []byte) (int, error) {
+	if body.r == nil && body.err == nil {
+		var r io.Reader = &countingReader{r: body.raw, n: &body.received}
+		for i := len(body.codings) - 1; i >= 0 && body.err == nil; i-- {
+			var decoded io.ReadCloser
+			decoded, body.err = body.decoders[body.codings[i]](r)
+			if body.err == nil {
+				body.closers = append(body.closers, decoded)
+				r = decoded
+			}
+		}
+		body.r = r
+	}
+	if body.err != nil {
+		return 0, body.err
+	}
+
+	n, err := body.r.Read(p)
+	body.decoded += int64(n)
+	if body.maxRatio > 0 && body.decoded > decompressionAllowance && float64(body.decoded) > body.maxRatio*float64(body.received) {
+		body.err = fmt.Errorf("%w: %s sent %d bytes decoded to more than %d", ErrDecompressionBomb, body.host, body.received, body.decoded)
+		return 0, body.err
+	}
+	return n, err
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+func (body *decodingBody) Close() error {
+	for _, closer := range body.closers {
+		closer.Close()
+	}
+	return body.raw.Close()
+}
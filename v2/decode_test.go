@@ -0,0 +1,176 @@
+package race
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoding(t *testing.T) {
+	gzipServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte("canonical"))
+		gz.Close()
+	}))
+	defer gzipServer.Close()
+
+	identityServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("canonical"))
+	}))
+	defer identityServer.Close()
+
+	reverseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "reverse")
+		w.Write([]byte("lacinonac"))
+	}))
+	defer reverseServer.Close()
+
+	reverse := func(r io.Reader) (io.ReadCloser, error) {
+		data, err := ioutil.ReadAll(r)
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+		return ioutil.NopCloser(bytes.NewReader(data)), err
+	}
+	r := New(WithDecoding(map[string]Decoder{"reverse": reverse}))
+	defer r.Close()
+
+	for _, url := range []string{gzipServer.URL, reverseServer.URL} {
+		req1, _ := http.NewRequest("GET", url, nil)
+		req2, _ := http.NewRequest("GET", identityServer.URL, nil)
+		req1.Header.Set("Accept-Encoding", "gzip, reverse")
+		req2.Header.Set("Accept-Encoding", "gzip, reverse")
+
+		res, err := r.DoubleRead(time.Second, req1, req2)
+		if err != nil {
+			t.Fatalf("Expected %s to agree with the identity server: %v", url, err)
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if string(body) != "canonical" || res.Header.Get("Content-Encoding") != "" {
+			t.Fatalf("Unexpected body %q", body)
+		}
+	}
+}
+
+func TestDecodingUnknownCoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		w.Write([]byte("raw"))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set("Accept-Encoding", "br")
+	res, err := New(WithDecoding(nil)).Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, _ := ioutil.ReadAll(res.Body)
+	if !strings.EqualFold(res.Header.Get("Content-Encoding"), "br") || string(body) != "raw" {
+		t.Fatal("Expected the response with an unknown coding to be kept")
+	}
+}
+
+func TestMaxDecompressionRatio(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write(make([]byte, 8<<20))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	for _, ratio := range []float64{0, 100, 100000} {
+		r := New(WithDecoding(nil), WithMaxDecompressionRatio(ratio))
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		res, err := r.Between(req)
+		if ratio == 100 {
+			r.Close()
+			if !errors.Is(err, ErrDecompressionBomb) {
+				t.Fatalf("Expected the attempt of the zip bomb to fail, got %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := io.Copy(ioutil.Discard, res.Body)
+		res.Body.Close()
+		r.Close()
+
+		if err != nil || n != 8<<20 {
+			t.Fatalf("Expected the body to be decoded with the ratio %v, got %d bytes and %v", ratio, n, err)
+		}
+	}
+}
+
+func TestMaxDecompressionRatio_RaceGoesOn(t *testing.T) {
+	bomb := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write(make([]byte, 8<<20))
+		gz.Close()
+	}))
+	defer bomb.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("content"))
+	}))
+	defer mirror.Close()
+
+	r := New(WithMaxDecompressionRatio(100))
+	defer r.Close()
+
+	// the transport would decompress the gzip of the bomb itself without Accept-Encoding
+	req1, _ := http.NewRequest("GET", bomb.URL, nil)
+	req2, _ := http.NewRequest("GET", mirror.URL, nil)
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil || string(body) != "content" {
+		t.Fatalf("Expected the mirror to win after the bomb failed, got %q and %v", body, err)
+	}
+}
+
+func TestMaxDecompressionRatio_Target(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write(make([]byte, 8<<20))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	r := New()
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", "http://placeholder/", nil)
+	if _, err := r.BetweenTargets(req, Target{URL: server.URL, MaxDecompressionRatio: 100}); !errors.Is(err, ErrDecompressionBomb) {
+		t.Fatalf("Expected the ratio of the target to be enforced, got %v", err)
+	}
+
+	res, err := r.BetweenTargets(req, Target{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+	if err != nil || n != 8<<20 {
+		t.Fatalf("Expected the other targets not to be limited, got %d bytes and %v", n, err)
+	}
+}
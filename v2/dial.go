@@ -0,0 +1,122 @@
+package race
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// DialOptions tunes how the connections to a target are dialed, racing is very sensitive
+// to connect timeouts and they differ a lot across platforms. Zero means the default
+type DialOptions struct {
+	// Timeout is the maximum time of a TCP connect, the OS default can be minutes
+	Timeout time.Duration
+	// KeepAlive is the interval of TCP keep-alive probes, negative disables them
+	KeepAlive time.Duration
+	// FallbackDelay is how long to wait for IPv6 before falling back to IPv4,
+	// negative disables the fallback
+	FallbackDelay time.Duration
+	// UserTimeout is how long transmitted data may remain unacknowledged before the connection
+	// is dropped: TCP_USER_TIMEOUT on Linux, TCP_MAXRT on Windows, TCP_CONNECTIONTIMEOUT and
+	// TCP_RXT_CONNDROPTIME on macOS, the last two are rounded up to seconds. It's ignored elsewhere
+	UserTimeout time.Duration
+}
+
+// WithDialOptions sets how the connections to the target host are dialed, the host
+// can be given with or without the port. The options are only applied when the transport
+// of the http client is an *http.Transport, which is cloned, the given client isn't modified.
+// If the transport has its own DialContext the connections are still dialed with it,
+// FallbackDelay doesn't apply then and the other options are applied to the dialed connection
+func WithDialOptions(host string, opts DialOptions) Option {
+	return func(race *Race) {
+		if race.dialOptions == nil {
+			race.dialOptions = make(map[string]DialOptions)
+			race.wrapDialer()
+		}
+		race.dialOptions[host] = opts
+	}
+}
+
+func (race *Race) wrapDialer() {
+	base, ok := race.client.Transport.(*http.Transport)
+	if race.client.Transport == nil {
+		base, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if !ok {
+		return
+	}
+
+	transport := base.Clone()
+	fallback := transport.DialContext
+	// the dialer of the default transport is replaced rather than wrapped
+	custom := race.client.Transport != nil && fallback != nil
+	if fallback == nil {
+		fallback = (&net.Dialer{}).DialContext
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		opts, ok := race.dialOptions[addr]
+		if !ok {
+			host, _, _ := net.SplitHostPort(addr)
+			opts, ok = race.dialOptions[host]
+		}
+		if !ok {
+			return fallback(ctx, network, addr)
+		}
+		if custom {
+			return dialTuned(ctx, fallback, network, addr, opts)
+		}
+
+		dialer := &net.Dialer{
+			Timeout:       opts.Timeout,
+			KeepAlive:     opts.KeepAlive,
+			FallbackDelay: opts.FallbackDelay,
+			Control:       userTimeout(opts.UserTimeout),
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	client := *race.client
+	client.Transport = transport
+	race.client = &client
+}
+
+// dialTuned dials with the custom dialer of a transport and applies the options to the connection
+func dialTuned(ctx context.Context, dial func(ctx context.Context, network, addr string) (net.Conn, error),
+	network, addr string, opts DialOptions) (net.Conn, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	conn, err := dial(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if tcp, ok := conn.(*net.TCPConn); ok && opts.KeepAlive != 0 {
+		err = tcp.SetKeepAlive(opts.KeepAlive > 0)
+		if err == nil && opts.KeepAlive > 0 {
+			err = tcp.SetKeepAlivePeriod(opts.KeepAlive)
+		}
+	}
+	if control := userTimeout(opts.UserTimeout); err == nil && control != nil {
+		if sc, ok := conn.(syscall.Conn); ok {
+			var raw syscall.RawConn
+			if raw, err = sc.SyscallConn(); err == nil {
+				err = control(network, addr, raw)
+			}
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// seconds rounds the duration up to whole seconds, for the socket options taking seconds
+func seconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,30 @@
+//go:build darwin
+
+package race
+
+import (
+	"syscall"
+	"time"
+)
+
+// userTimeout bounds the connection establishment (TCP_CONNECTIONTIMEOUT) and how long
+// retransmissions may go unacknowledged (TCP_RXT_CONNDROPTIME), both in seconds
+func userTimeout(timeout time.Duration) func(network, address string, c syscall.RawConn) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	return func(network, address string, c syscall.RawConn) error {
+		var sockErr error
+		err := c.Control(func(fd uintptr) {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_CONNECTIONTIMEOUT, seconds(timeout))
+			if sockErr == nil {
+				sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_RXT_CONNDROPTIME, seconds(timeout))
+			}
+		})
+		if err != nil {
+			return err
+		}
+		return sockErr
+	}
+}
@@ -0,0 +1,28 @@
+//go:build linux
+
+package race
+
+import (
+	"syscall"
+	"time"
+)
+
+// tcpUserTimeout is TCP_USER_TIMEOUT from linux/tcp.h, the syscall package doesn't export it
+const tcpUserTimeout = 0x12
+
+func userTimeout(timeout time.Duration) func(network, address string, c syscall.RawConn) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	return func(network, address string, c syscall.RawConn) error {
+		var sockErr error
+		err := c.Control(func(fd uintptr) {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, tcpUserTimeout, int(timeout.Milliseconds()))
+		})
+		if err != nil {
+			return err
+		}
+		return sockErr
+	}
+}
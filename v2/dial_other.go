@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package race
+
+import (
+	"syscall"
+	"time"
+)
+
+// userTimeout is only supported on Linux, macOS and Windows
+func userTimeout(timeout time.Duration) func(network, address string, c syscall.RawConn) error {
+	return nil
+}
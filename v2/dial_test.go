@@ -0,0 +1,81 @@
+package race
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDialOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{}
+	r := NewWithClient(client, WithDialOptions(u.Hostname(), DialOptions{
+		Timeout:     time.Second,
+		KeepAlive:   -1,
+		UserTimeout: time.Second,
+	}))
+	if client.Transport != nil {
+		t.Fatal("Expected the given client not to be modified")
+	}
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
+
+func TestDialOptions_CustomDialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dials int32
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	r := NewWithClient(client, WithDialOptions(u.Hostname(), DialOptions{
+		Timeout:     time.Second,
+		KeepAlive:   time.Second,
+		UserTimeout: time.Second,
+	}))
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if dials := atomic.LoadInt32(&dials); dials != 1 {
+		t.Fatalf("Expected the custom dialer to be used, got %d dials", dials)
+	}
+}
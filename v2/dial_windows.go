@@ -0,0 +1,29 @@
+//go:build windows
+
+package race
+
+import (
+	"syscall"
+	"time"
+)
+
+// tcpMaxRT is TCP_MAXRT from ws2ipdef.h, the syscall package doesn't export it
+const tcpMaxRT = 5
+
+// userTimeout bounds how long retransmissions may go unacknowledged (TCP_MAXRT), in seconds
+func userTimeout(timeout time.Duration) func(network, address string, c syscall.RawConn) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	return func(network, address string, c syscall.RawConn) error {
+		var sockErr error
+		err := c.Control(func(fd uintptr) {
+			sockErr = syscall.SetsockoptInt(syscall.Handle(fd), syscall.IPPROTO_TCP, tcpMaxRT, seconds(timeout))
+		})
+		if err != nil {
+			return err
+		}
+		return sockErr
+	}
+}
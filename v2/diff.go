@@ -0,0 +1,164 @@
+package race
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Diff is the difference between the response of the primary and the one of a shadow, see WithDiff.
+// The bodies are truncated to the size given to WithDiff and the responses are already closed
+type Diff struct {
+	Primary     *http.Response
+	PrimaryBody []byte
+	// Shadow is nil if the shadow failed with ShadowErr
+	Shadow     *http.Response
+	ShadowBody []byte
+	ShadowErr  error
+	// Status reports whether the status codes differ
+	Status bool
+	// Headers are the sorted names of the headers whose values differ
+	Headers []string
+	// Body reports whether the bodies aren't equivalent according to the Comparator of the Race
+	Body bool
+	// Incomplete reports that the caller didn't read the body of the primary to its end or to the
+	// size given to WithDiff before closing it, or didn't close it in time, then the bodies aren't compared
+	Incomplete bool
+}
+
+// Equal reports whether the shadow succeeded, the body of the primary was captured and no difference was found
+func (d Diff) Equal() bool {
+	return d.ShadowErr == nil && !d.Incomplete && !d.Status && len(d.Headers) == 0 && !d.Body
+}
+
+type diff struct {
+	maxBytes int64
+	fn       func(Diff)
+	ignore   map[string]bool
+}
+
+// WithDiff compares the response of every shadow of Shadow with the one of the primary, e.g. to validate
+// a rewritten service against the legacy one in production, and calls fn with their differences. At most
+// maxBytes of every body are compared, the body of the primary is captured while the caller reads it and
+// the diffs are reported once it's closed. If it isn't closed within the budget of WithLoserCompletion,
+// or captureTimeout without one, the diffs are Incomplete. The given headers aren't compared, e.g. Date
+func WithDiff(maxBytes int64, fn func(Diff), ignoreHeaders ...string) Option {
+	ignore := make(map[string]bool, len(ignoreHeaders))
+	for _, name := range ignoreHeaders {
+		ignore[http.CanonicalHeaderKey(name)] = true
+	}
+
+	return func(race *Race) {
+		race.diff = &diff{maxBytes: maxBytes, fn: fn, ignore: ignore}
+	}
+}
+
+// primaryCapture is the response of the primary of Shadow, shared by the diffs of its shadows
+type primaryCapture struct {
+	done     chan struct{}
+	res      *http.Response
+	captured chan capture
+
+	once     sync.Once
+	body     []byte
+	complete bool
+}
+
+// set records the outcome of the primary and captures its body, it must be called once
+func (p *primaryCapture) set(res *http.Response, maxBytes int64) {
+	if res != nil {
+		p.res = res
+		p.captured = make(chan capture, 1)
+		res.Body = &captureBody{ReadCloser: res.Body, remaining: maxBytes, captured: p.captured}
+	}
+	close(p.done)
+}
+
+// waitPrimary returns the response of the primary and its captured body once the caller closes it, for up
+// to the loser budget or captureTimeout. complete is false if it isn't closed in time or wasn't read to its
+// end or to the limit, ok is false if the primary failed or the Race was closed
+func (race *Race) waitPrimary(p *primaryCapture) (res *http.Response, body []byte, complete bool, ok bool) {
+	select {
+	case <-p.done:
+	case <-race.ctx.Done():
+		return nil, nil, false, false
+	}
+	if p.res == nil {
+		return nil, nil, false, false
+	}
+
+	p.once.Do(func() {
+		wait := race.loserBudget
+		if wait <= 0 {
+			wait = captureTimeout
+		}
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case captured := <-p.captured:
+			p.body = captured.body
+			p.complete = captured.complete
+		case <-timer.C:
+		case <-race.ctx.Done():
+		}
+	})
+	if race.ctx.Err() != nil {
+		return nil, nil, false, false
+	}
+	return p.res, p.body, p.complete, true
+}
+
+// reportDiff compares the shadow with the primary once its body is captured
+func (race *Race) reportDiff(p *primaryCapture, shadow *http.Response, shadowBody []byte, shadowErr error) {
+	primary, primaryBody, complete, ok := race.waitPrimary(p)
+	if !ok {
+		return
+	}
+
+	d := Diff{
+		Primary:     primary,
+		PrimaryBody: primaryBody,
+		Shadow:      shadow,
+		ShadowBody:  shadowBody,
+		ShadowErr:   shadowErr,
+		Incomplete:  !complete,
+	}
+	if shadow != nil {
+		d.Status = primary.StatusCode != shadow.StatusCode
+		d.Headers = race.diff.headers(primary.Header, shadow.Header)
+		d.Body = complete && !race.equal(primaryBody, shadowBody)
+	}
+	race.diff.fn(d)
+}
+
+// headers returns the sorted names of the headers whose values differ
+func (d *diff) headers(a, b http.Header) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, header := range []http.Header{a, b} {
+		for name := range header {
+			name = http.CanonicalHeaderKey(name)
+			if seen[name] || d.ignore[name] {
+				continue
+			}
+			seen[name] = true
+			if !reflect.DeepEqual(a.Values(name), b.Values(name)) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readShadow reads up to maxBytes of the body of the shadow for its diff and drains the rest
+func readShadow(res *http.Response, maxBytes int64) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, maxBytes))
+	io.Copy(ioutil.Discard, res.Body)
+	return body, err
+}
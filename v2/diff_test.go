@@ -0,0 +1,110 @@
+package race
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	legacy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Version", "1")
+		w.Write([]byte("total=10"))
+	}))
+	defer legacy.Close()
+	rewrite := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Version", "2")
+		w.Write([]byte("total=11"))
+	}))
+	defer rewrite.Close()
+	same := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Version", "1")
+		w.Write([]byte("total=10"))
+	}))
+	defer same.Close()
+
+	diffs := make(chan Diff, 2)
+	r := New(WithDiff(1024, func(d Diff) { diffs <- d }, "Date"))
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", legacy.URL, nil)
+	shadow1, _ := http.NewRequest("GET", rewrite.URL, nil)
+	shadow2, _ := http.NewRequest("GET", same.URL, nil)
+	res, err := r.Shadow(req, shadow1, shadow2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "total=10" {
+		t.Fatalf("Expected the body of the primary, got %q", body)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case d := <-diffs:
+			if string(d.PrimaryBody) != "total=10" {
+				t.Fatalf("Expected the body of the primary to be captured, got %q", d.PrimaryBody)
+			}
+			if d.Shadow.Request.URL.Host == shadow2.URL.Host {
+				if !d.Equal() {
+					t.Fatalf("Expected no difference, got %+v", d)
+				}
+				continue
+			}
+			if d.Status || !d.Body || !reflect.DeepEqual(d.Headers, []string{"X-Version"}) || d.Equal() {
+				t.Fatalf("Expected the body and the version to differ, got %+v", d)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the diffs of the shadows")
+		}
+	}
+}
+
+func TestDiff_Incomplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("total=10"))
+	}))
+	defer server.Close()
+
+	diffs := make(chan Diff, 2)
+	r := New(WithDiff(1024, func(d Diff) { diffs <- d }), WithLoserCompletion(50*time.Millisecond))
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	shadow, _ := http.NewRequest("GET", server.URL, nil)
+	res, err := r.Shadow(req, shadow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	select {
+	case d := <-diffs:
+		if !d.Incomplete || d.Body || d.Equal() {
+			t.Fatalf("Expected the diff of the unread primary to be incomplete, got %+v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the diff to be reported once the budget elapsed")
+	}
+
+	req, _ = http.NewRequest("GET", server.URL, nil)
+	shadow, _ = http.NewRequest("GET", server.URL, nil)
+	res, err = r.Shadow(req, shadow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	select {
+	case d := <-diffs:
+		if !d.Incomplete || d.Equal() {
+			t.Fatalf("Expected the diff of the primary closed before its end to be incomplete, got %+v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the diff of the closed primary")
+	}
+}
@@ -0,0 +1,67 @@
+package race
+
+import "net/http"
+
+// FailureDomains returns a strategy that hedges across failure domains, e.g. zones or providers,
+// given by the domain function: the primary is kept and the hedges alternate between the domains,
+// starting with the domains other than the primary's, so an outage of a domain can't take out both
+// the primary and its first hedge. The targets are ordered by the next strategy first if it's not nil,
+// it's also notified of the attempts
+func FailureDomains(domain func(target *http.Request) string, next Strategy) Strategy {
+	return &failureDomains{chain: chain{next: next}, domain: domain}
+}
+
+// TargetDomains returns the domain function of FailureDomains for the targets, the domain of
+// the Target of a request given by TargetOf, or of the target with the same host
+func TargetDomains(targets ...Target) func(target *http.Request) string {
+	domains := make(map[string]string)
+	for _, target := range targets {
+		if req, err := http.NewRequest(http.MethodGet, target.URL, nil); err == nil {
+			domains[req.URL.Host] = target.Domain
+		}
+	}
+	return func(req *http.Request) string {
+		if target, ok := TargetOf(req); ok {
+			return target.Domain
+		}
+		return domains[req.URL.Host]
+	}
+}
+
+type failureDomains struct {
+	chain
+	domain func(target *http.Request) string
+}
+
+func (fd *failureDomains) Order(targets []*http.Request) []*http.Request {
+	ordered := fd.order(targets)
+	// a single hedge can't be diversified
+	if len(ordered) < 3 {
+		return ordered
+	}
+
+	primary := fd.domain(ordered[0])
+	var domains []string
+	groups := make(map[string][]*http.Request)
+	for _, target := range ordered[1:] {
+		d := fd.domain(target)
+		if _, ok := groups[d]; !ok && d != primary {
+			domains = append(domains, d)
+		}
+		groups[d] = append(groups[d], target)
+	}
+	// the domain of the primary comes last
+	domains = append(domains, primary)
+
+	diversified := make([]*http.Request, 1, len(ordered))
+	diversified[0] = ordered[0]
+	for len(diversified) < len(ordered) {
+		for _, d := range domains {
+			if group := groups[d]; len(group) > 0 {
+				diversified = append(diversified, group[0])
+				groups[d] = group[1:]
+			}
+		}
+	}
+	return diversified
+}
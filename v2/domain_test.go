@@ -0,0 +1,36 @@
+package race
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFailureDomains(t *testing.T) {
+	targets := []Target{
+		{URL: "http://a1", Domain: "zone-a"},
+		{URL: "http://a2", Domain: "zone-a"},
+		{URL: "http://a3", Domain: "zone-a"},
+		{URL: "http://b1", Domain: "zone-b"},
+		{URL: "http://c1", Domain: "zone-c"},
+		{URL: "http://b2", Domain: "zone-b"},
+	}
+	var reqs []*http.Request
+	for _, target := range targets {
+		req, _ := http.NewRequest("GET", target.URL, nil)
+		reqs = append(reqs, req)
+	}
+
+	strategy := FailureDomains(TargetDomains(targets...), nil)
+	var hosts []string
+	for _, target := range strategy.Order(reqs) {
+		hosts = append(hosts, target.URL.Host)
+	}
+
+	if got := strings.Join(hosts, ","); got != "a1,b1,c1,a2,b2,a3" {
+		t.Fatalf("Expected the hedges to alternate between the domains, got %s", got)
+	}
+	if reqs[1].URL.Host != "a2" {
+		t.Fatal("Expected the targets not to be modified")
+	}
+}
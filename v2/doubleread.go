@@ -0,0 +1,81 @@
+package race
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrMismatch is returned when the targets returned different data
+var ErrMismatch = errors.New("race: the targets returned different data")
+
+type bufferedResponse struct {
+	index int
+	res   *http.Response
+	body  []byte
+}
+
+// DoubleRead is for critical reads, it makes the requests simultaneously and returns a response
+// only when two different hosts returned equivalent bodies, according to the Comparator of the Race.
+// If the window elapses before that, or the other requests failed, the first successful response is
+// returned. If several requests succeeded and none of them agree ErrMismatch is returned.
+// The bodies are read by the attempts and the body of the response is buffered
+func (race *Race) DoubleRead(window time.Duration, reqs ...*http.Request) (*http.Response, error) {
+	reqs = race.order(reqs)
+	if len(reqs) == 0 {
+		return nil, ErrNoRequests
+	}
+	if err := race.checkIdempotent(reqs); err != nil {
+		return nil, err
+	}
+
+	c := race.newContest(race.client.Timeout)
+	c.buffered = true
+	defer c.finish()
+
+	c.startAll(reqs)
+
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+
+	var reads []bufferedResponse
+	errs := make([]error, len(reqs))
+	failures := 0
+	expired := false
+	for len(reads)+failures < len(reqs) {
+		select {
+		case o := <-c.results:
+			if o.err != nil {
+				errs[o.index] = o.err
+				failures++
+				continue
+			}
+
+			if expired {
+				return c.win(o.index, reqs[o.index], o.res)
+			}
+			for _, read := range reads {
+				if reqs[read.index].URL.Host != reqs[o.index].URL.Host && race.equal(read.body, o.body) {
+					return c.win(read.index, reqs[read.index], read.res)
+				}
+			}
+			reads = append(reads, bufferedResponse{index: o.index, res: o.res, body: o.body})
+		case <-timer.C:
+			expired = true
+			// fall back to a single source
+			if len(reads) > 0 {
+				return c.win(reads[0].index, reqs[reads[0].index], reads[0].res)
+			}
+		}
+	}
+
+	switch len(reads) {
+	case 0:
+		return nil, failedAttempts(reqs, errs)
+	case 1:
+		// the others failed, there is nothing to agree with
+		return c.win(reads[0].index, reqs[reads[0].index], reads[0].res)
+	default:
+		return nil, ErrMismatch
+	}
+}
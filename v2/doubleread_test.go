@@ -0,0 +1,111 @@
+package race_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mostafa-asg/race/v2"
+	"github.com/mostafa-asg/race/v2/racetest"
+)
+
+func TestDoubleRead(t *testing.T) {
+	servers := racetest.NewServers(
+		racetest.Profile{Body: []byte("v1")},
+		racetest.Profile{Latency: 50 * time.Millisecond, Body: []byte("v1")},
+		racetest.Profile{Body: []byte("v2")},
+		racetest.Profile{Latency: 2 * time.Second, Body: []byte("v1")},
+	)
+	defer servers.Close()
+	fast, slow, other, verySlow := 0, 1, 2, 3
+
+	tests := []struct {
+		name    string
+		servers []int
+		body    string
+		err     error
+	}{
+		{"agreement", []int{fast, slow}, "v1", nil},
+		{"mismatch", []int{fast, other}, "", race.ErrMismatch},
+		{"single source after the window", []int{fast, verySlow}, "v1", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			all := servers.Requests("/")
+			var reqs []*http.Request
+			for _, i := range test.servers {
+				reqs = append(reqs, all[i])
+			}
+
+			start := time.Now()
+			res, err := race.New().DoubleRead(200*time.Millisecond, reqs...)
+			if err != test.err {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("Expected the window to bound the latency, took %v", elapsed)
+			}
+
+			body, err := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != test.body {
+				t.Fatalf("Expected %q, got %q", test.body, body)
+			}
+		})
+	}
+}
+
+func TestDoubleRead_Adapter(t *testing.T) {
+	// the responses of the adapter have no Request
+	adapter := race.AdapterFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte("v1"))),
+		}, nil
+	})
+
+	a, _ := http.NewRequest("GET", "mem://a/key", nil)
+	b, _ := http.NewRequest("GET", "mem://b/key", nil)
+	res, err := race.New(race.WithAdapter("mem", adapter)).DoubleRead(time.Second, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
+
+func TestDoubleRead_SlowBody(t *testing.T) {
+	slowBody := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		select {
+		case <-time.After(2 * time.Second):
+			w.Write([]byte("v1"))
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowBody.Close()
+	servers := racetest.NewServers(racetest.Profile{Latency: 20 * time.Millisecond, Body: []byte("v1")})
+	defer servers.Close()
+
+	req, _ := http.NewRequest("GET", slowBody.URL, nil)
+	start := time.Now()
+	res, err := race.New().DoubleRead(100*time.Millisecond, req, servers.Requests("/")[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the slow body not to hold the window, took %v", elapsed)
+	}
+}
@@ -0,0 +1,185 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// ErrNoRequests is returned when there is no request to race
+var ErrNoRequests = errors.New("race: no requests to race")
+
+// ErrNotAttempted is the error of the requests of a failed race that were never started
+var ErrNotAttempted = errors.New("race: not attempted")
+
+// AttemptError is the error of one of the requests of a failed race
+type AttemptError struct {
+	// Index is the position of the request in the race
+	Index   int
+	Request *http.Request
+	Err     error
+}
+
+func (e *AttemptError) Error() string {
+	return fmt.Sprintf("attempt %d (%s %s): %v", e.Index, e.Request.Method, e.Request.URL.Redacted(), e.Err)
+}
+
+func (e *AttemptError) Unwrap() error {
+	return e.Err
+}
+
+// ErrClosed is the cause of the cancellation of the races of a closed Race
+var ErrClosed = errors.New("race: closed")
+
+// Failure classifies why a race failed, so callers can choose a remediation
+// programmatically
+type Failure int
+
+const (
+	// FailureUnknown is used when the errors couldn't be classified
+	FailureUnknown Failure = iota
+	// FailureDNS means the hosts couldn't be resolved,
+	// usually retrying won't help and failing over to another provider is the way to go
+	FailureDNS
+	// FailureTimeout means the targets didn't answer in time,
+	// retrying later is reasonable
+	FailureTimeout
+	// FailureConnection means the connections were refused or reset,
+	// the targets are probably down and someone should be alerted
+	FailureConnection
+	// FailureCanceled means the race was canceled by the caller
+	FailureCanceled
+	// FailureSaturated means the targets had no free stream, see WithMaxConcurrentStreams
+	FailureSaturated
+	// FailureRateLimited means the attempts were skipped by the rate limiters
+	// of the targets, see WithRateLimit
+	FailureRateLimited
+	// FailureQuotaExceeded means the targets have used up their quota, see WithQuota
+	FailureQuotaExceeded
+	// FailureBudgetExceeded means the request served by the race has used up its attempts,
+	// see ContextWithAttemptBudget
+	FailureBudgetExceeded
+	// FailureMixed means the attempts failed for different reasons
+	FailureMixed
+)
+
+func (f Failure) String() string {
+	switch f {
+	case FailureDNS:
+		return "dns"
+	case FailureTimeout:
+		return "timeout"
+	case FailureConnection:
+		return "connection"
+	case FailureCanceled:
+		return "canceled"
+	case FailureSaturated:
+		return "saturated"
+	case FailureRateLimited:
+		return "rate limited"
+	case FailureQuotaExceeded:
+		return "quota exceeded"
+	case FailureBudgetExceeded:
+		return "budget exceeded"
+	case FailureMixed:
+		return "mixed"
+	default:
+		return "unknown"
+	}
+}
+
+// Classify returns the classification of an error returned by a race,
+// if all the attempts failed for the same reason that reason is returned,
+// otherwise FailureMixed
+func Classify(err error) Failure {
+	var merr *multierror.Error
+	if errors.As(err, &merr) {
+		return classifyAll(merr.Errors)
+	}
+
+	return classify(err)
+}
+
+func classifyAll(errs []error) Failure {
+	failure := FailureUnknown
+	classified := false
+	for _, err := range errs {
+		// missing attempts don't tell why the race failed
+		if errors.Is(err, ErrNotAttempted) {
+			continue
+		}
+
+		if !classified {
+			failure = classify(err)
+			classified = true
+		} else if classify(err) != failure {
+			return FailureMixed
+		}
+	}
+
+	return failure
+}
+
+func classify(err error) Failure {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return FailureUnknown
+	case errors.As(err, &dnsErr):
+		return FailureDNS
+	case errors.Is(err, ErrTargetSaturated), errors.Is(err, ErrQueueTimeout), errors.Is(err, ErrTenantLimited):
+		return FailureSaturated
+	case errors.Is(err, ErrRateLimited):
+		return FailureRateLimited
+	case errors.Is(err, ErrQuotaExceeded):
+		return FailureQuotaExceeded
+	case errors.Is(err, ErrBudgetExceeded):
+		return FailureBudgetExceeded
+	case errors.Is(err, ErrFirstByteTimeout):
+		return FailureTimeout
+	case errors.Is(err, context.Canceled):
+		return FailureCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailureTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return FailureTimeout
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET):
+		return FailureConnection
+	default:
+		return FailureUnknown
+	}
+}
+
+// failedAttempts aggregates the errors of the requests of a race in their order,
+// the requests without an error were not attempted
+func failedAttempts(reqs []*http.Request, errs []error) error {
+	slots := make([]error, len(reqs))
+	for i, req := range reqs {
+		err := errs[i]
+		if err == nil {
+			err = ErrNotAttempted
+		}
+		slots[i] = &AttemptError{Index: i, Request: req, Err: err}
+	}
+
+	return failed(slots...)
+}
+
+// failed aggregates the errors of all attempts, the message starts with their classification
+func failed(errs ...error) error {
+	allerrors := &multierror.Error{
+		ErrorFormat: formatErrors,
+	}
+	return multierror.Append(allerrors, errs...)
+}
+
+func formatErrors(errs []error) string {
+	return "race failed (" + classifyAll(errs).String() + "): " + multierror.ListFormatFunc(errs)
+}
@@ -0,0 +1,129 @@
+package race
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const unresolvableDomain = "http://CrazyAndStrangeAndUnresolvableDomain"
+
+func TestClassify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	r := NewWithClient(&http.Client{
+		Timeout: 50 * time.Millisecond,
+	})
+
+	tests := []struct {
+		name    string
+		urls    []string
+		failure Failure
+	}{
+		{"dns", []string{unresolvableDomain, unresolvableDomain}, FailureDNS},
+		{"timeout", []string{server.URL, server.URL}, FailureTimeout},
+		{"mixed", []string{unresolvableDomain, server.URL}, FailureMixed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var reqs []*http.Request
+			for _, url := range test.urls {
+				req, err := http.NewRequest("GET", url, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				reqs = append(reqs, req)
+			}
+
+			_, err := r.Between(reqs...)
+			if err == nil {
+				t.Fatal("Expected the race to fail")
+			}
+			if failure := Classify(err); failure != test.failure {
+				t.Fatalf("Expected %v, got %v", test.failure, failure)
+			}
+			if !strings.HasPrefix(err.Error(), "race failed ("+test.failure.String()+")") {
+				t.Fatalf("Expected the message to start with the classification, got %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestClassify_SingleError(t *testing.T) {
+	if failure := Classify(ErrTargetSaturated); failure != FailureSaturated {
+		t.Fatalf("Expected %v, got %v", FailureSaturated, failure)
+	}
+	if failure := Classify(errors.New("boom")); failure != FailureUnknown {
+		t.Fatalf("Expected %v, got %v", FailureUnknown, failure)
+	}
+}
+
+func TestAttemptErrorsOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	first, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := http.NewRequest("GET", unresolvableDomain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the second request fails long before the first one
+	_, err = FirstThenStart(first, 10*time.Millisecond, second)
+	multiError, ok := err.(*multierror.Error)
+	if !ok {
+		t.Fatal("Expected error of type *multierror.Error")
+	}
+
+	for i, err := range multiError.Errors {
+		var attemptErr *AttemptError
+		if !errors.As(err, &attemptErr) || attemptErr.Index != i {
+			t.Fatalf("Expected the error of attempt %d, got %v", i, err)
+		}
+	}
+	if Classify(multiError.Errors[1]) != FailureDNS {
+		t.Fatalf("Expected the DNS failure of the second request last, got %v", multiError.Errors[1])
+	}
+}
+
+func TestFailedAttempts_NotAttempted(t *testing.T) {
+	first, err := http.NewRequest("GET", unresolvableDomain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := http.NewRequest("GET", unresolvableDomain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = failedAttempts([]*http.Request{first, second}, []error{ErrTargetSaturated, nil})
+	multiError := err.(*multierror.Error)
+	if !errors.Is(multiError.Errors[1], ErrNotAttempted) {
+		t.Fatalf("Expected the missing slot to be marked as not attempted, got %v", multiError.Errors[1])
+	}
+	if Classify(err) != FailureSaturated {
+		t.Fatalf("Expected the missing slot to be ignored by Classify, got %v", Classify(err))
+	}
+}
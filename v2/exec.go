@@ -0,0 +1,57 @@
+package race
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// Commands runs the commands simultaneously, e.g. several resolvers or tools giving the same answer,
+// and returns the standard output of the first one to exit successfully, the others are killed. The
+// commands must not be started nor have a Stdout. If all of them fail the errors are aggregated like
+// the ones of a failed race
+func Commands(ctx context.Context, cmds ...*exec.Cmd) ([]byte, error) {
+	fns := make([]func(ctx context.Context) ([]byte, error), len(cmds))
+	for i, cmd := range cmds {
+		cmd := cmd
+		fns[i] = func(ctx context.Context) ([]byte, error) {
+			return runCommand(ctx, cmd)
+		}
+	}
+
+	output, _, cancel, err := first(ctx, "command", fns, nil)
+	if err != nil {
+		return nil, err
+	}
+	cancel()
+	return output, nil
+}
+
+// runCommand runs the command and kills it when the context is done
+func runCommand(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	if cmd.Stdout != nil {
+		return nil, errors.New("race: Stdout already set")
+	}
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	select {
+	case err := <-exited:
+		if err != nil {
+			return nil, err
+		}
+		return stdout.Bytes(), nil
+	case <-ctx.Done():
+		cmd.Process.Kill()
+		<-exited
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,42 @@
+package race
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCommands(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	marker := filepath.Join(t.TempDir(), "finished")
+	slow := exec.Command("sh", "-c", "sleep 0.3; touch "+marker)
+	failing := exec.Command("sh", "-c", "exit 1")
+	fast := exec.Command("sh", "-c", "sleep 0.05; echo fast")
+
+	start := time.Now()
+	output, err := Commands(context.Background(), slow, failing, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "fast\n" {
+		t.Fatalf("Expected the output of the fast command, got %q", output)
+	}
+	if time.Since(start) > 250*time.Millisecond {
+		t.Fatal("Expected the slow command not to be waited for")
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("Expected the slow command to be killed")
+	}
+
+	if _, err := Commands(context.Background(), exec.Command("sh", "-c", "exit 1")); err == nil {
+		t.Fatal("Expected the race of failing commands to fail")
+	}
+}
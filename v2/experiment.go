@@ -0,0 +1,131 @@
+package race
+
+import (
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// experimentSamples is the number of latencies kept per cohort
+const experimentSamples = 1024
+
+// Cohort is the latencies of the races of a cohort of the experiment, or of the attempts made to
+// a host, the percentiles are computed over the last ones
+type Cohort struct {
+	Races    int
+	Failures int
+	P50      time.Duration
+	P90      time.Duration
+	P95      time.Duration
+	P99      time.Duration
+}
+
+// ExperimentStats compares the races with the calls of the control cohort, made without racing
+type ExperimentStats struct {
+	Raced   Cohort
+	Control Cohort
+}
+
+type experiment struct {
+	control float64
+
+	mu      sync.Mutex
+	cohorts [2]cohort
+}
+
+type cohort struct {
+	races     int
+	failures  int
+	latencies []time.Duration
+	next      int
+}
+
+// WithExperiment makes the given fraction of the races (between 0 and 1) the control cohort
+// of an experiment: only their primary is requested, without racing, and the latencies of both
+// cohorts are recorded, so the benefit of racing can be measured in production, see Experiment
+func WithExperiment(control float64) Option {
+	return func(race *Race) {
+		race.experiment = &experiment{control: control}
+	}
+}
+
+// Experiment returns the stats of the experiment, they are empty without WithExperiment
+func (race *Race) Experiment() ExperimentStats {
+	if race.experiment == nil {
+		return ExperimentStats{}
+	}
+
+	e := race.experiment
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return ExperimentStats{
+		Raced:   e.cohorts[0].stats(),
+		Control: e.cohorts[1].stats(),
+	}
+}
+
+// sample chooses whether the race is in the control cohort, whose only tier is the primary
+func (e *experiment) sample(tiers []Tier) ([]Tier, bool) {
+	if e == nil || rand.Float64() >= e.control {
+		return tiers, false
+	}
+
+	for _, tier := range tiers {
+		if len(tier.Requests) > 0 {
+			return []Tier{{Requests: []*http.Request{tier.Requests[0]}}}, true
+		}
+	}
+	return tiers, true
+}
+
+func (e *experiment) observe(control bool, elapsed time.Duration, err error) {
+	if e == nil {
+		return
+	}
+
+	i := 0
+	if control {
+		i = 1
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.cohorts[i].add(elapsed, err)
+}
+
+// add records the latency of a race of the cohort
+func (c *cohort) add(elapsed time.Duration, err error) {
+	c.races++
+	if err != nil {
+		c.failures++
+	}
+	if len(c.latencies) < experimentSamples {
+		c.latencies = append(c.latencies, elapsed)
+	} else {
+		c.latencies[c.next] = elapsed
+		c.next = (c.next + 1) % experimentSamples
+	}
+}
+
+func (c *cohort) stats() Cohort {
+	stats := Cohort{Races: c.races, Failures: c.failures}
+	if len(c.latencies) == 0 {
+		return stats
+	}
+
+	sorted := append([]time.Duration(nil), c.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	percentile := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+
+	stats.P50 = percentile(0.5)
+	stats.P90 = percentile(0.9)
+	stats.P95 = percentile(0.95)
+	stats.P99 = percentile(0.99)
+	return stats
+}
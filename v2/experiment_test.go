@@ -0,0 +1,54 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExperiment(t *testing.T) {
+	var hedgeHits int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer primary.Close()
+	hedge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hedgeHits, 1)
+	}))
+	defer hedge.Close()
+
+	tests := []struct {
+		control  float64
+		raced    int
+		controls int
+	}{
+		{0, 5, 0},
+		{1, 0, 5},
+	}
+
+	for _, test := range tests {
+		atomic.StoreInt32(&hedgeHits, 0)
+		r := New(WithExperiment(test.control))
+		for i := 0; i < 5; i++ {
+			req1, _ := http.NewRequest("GET", primary.URL, nil)
+			req2, _ := http.NewRequest("GET", hedge.URL, nil)
+			res, err := r.FirstThenStart(req1, 0, req2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+		}
+		r.Close()
+
+		stats := r.Experiment()
+		if stats.Raced.Races != test.raced || stats.Control.Races != test.controls {
+			t.Fatalf("Expected %d raced and %d control races, got %+v", test.raced, test.controls, stats)
+		}
+		if test.controls > 0 && atomic.LoadInt32(&hedgeHits) != 0 {
+			t.Fatal("Expected the control cohort not to race")
+		}
+		if test.controls > 0 && (stats.Control.P50 <= 0 || stats.Control.P99 < stats.Control.P50 || stats.Control.P99 > time.Second) {
+			t.Fatalf("Unexpected percentiles %+v", stats.Control)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package race
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Explore returns a strategy that only races the given number of fastest targets,
+// except for a fraction of the races (between 0 and 1) which include all the targets,
+// so the latency of the currently losing ones stays fresh, multi-armed bandit style.
+// At least the fastest target is raced. Targets are identified by their host
+func Explore(fraction float64, fastest int) Strategy {
+	if fastest < 1 {
+		fastest = 1
+	}
+	return &explore{
+		fraction:  fraction,
+		fastest:   fastest,
+		latencies: newLatencies(),
+	}
+}
+
+type explore struct {
+	fraction  float64
+	fastest   int
+	latencies *latencies
+}
+
+func (e *explore) Order(targets []*http.Request) []*http.Request {
+	sorted := e.latencies.sorted(targets)
+	if len(sorted) <= e.fastest || rand.Float64() < e.fraction {
+		return sorted
+	}
+
+	return sorted[:e.fastest]
+}
+
+func (e *explore) Started(target *http.Request) {}
+
+func (e *explore) Finished(target *http.Request, elapsed time.Duration, err error) {
+	e.latencies.observe(target.URL.Host, elapsed, err)
+}
@@ -0,0 +1,57 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestExplore(t *testing.T) {
+	var targets []*http.Request
+	for _, host := range []string{"http://slow", "http://fast", "http://medium"} {
+		req, err := http.NewRequest("GET", host, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, req)
+	}
+
+	exploit := Explore(0, 2)
+	observer := exploit.(AttemptObserver)
+	observer.Finished(targets[0], 300*time.Millisecond, nil)
+	observer.Finished(targets[1], 10*time.Millisecond, nil)
+	observer.Finished(targets[2], 50*time.Millisecond, context.Canceled)
+
+	ordered := exploit.Order(targets)
+	if len(ordered) != 2 || ordered[0] != targets[1] || ordered[1] != targets[2] {
+		t.Fatal("Expected only the 2 fastest targets, fastest first")
+	}
+
+	if len(Explore(1, 2).Order(targets)) != 3 {
+		t.Fatal("Expected all the targets when exploring")
+	}
+}
+
+func TestExplore_Fastest(t *testing.T) {
+	var targets []*http.Request
+	for _, host := range []string{"http://a", "http://b"} {
+		req, err := http.NewRequest("GET", host, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, req)
+	}
+
+	for _, fastest := range []int{-1, 0, 1} {
+		if n := len(Explore(0, fastest).Order(targets)); n != 1 {
+			t.Fatalf("Expected the fastest target only for %d, got %d", fastest, n)
+		}
+	}
+	if n := len(Explore(0, 5).Order(targets)); n != 2 {
+		t.Fatalf("Expected all the targets, got %d", n)
+	}
+	if n := len(Explore(0, 0).Order(nil)); n != 0 {
+		t.Fatalf("Expected no targets, got %d", n)
+	}
+}
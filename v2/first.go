@@ -0,0 +1,93 @@
+package race
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type firstOutcome[T any] struct {
+	index int
+	value T
+	err   error
+}
+
+// Do calls the functions simultaneously, e.g. queries, RPCs or cache lookups, and returns the value of
+// the first one to succeed. Each function gets its own child of the context, the others are canceled as
+// soon as the winner is known and the context of the winner is canceled when Do returns, so the value
+// must not depend on it. If all of them fail the errors are aggregated like the ones of a failed race
+func Do[T any](ctx context.Context, fns ...func(ctx context.Context) (T, error)) (T, error) {
+	value, _, cancel, err := first(ctx, "attempt", fns, nil)
+	if err != nil {
+		return value, err
+	}
+	cancel()
+	return value, nil
+}
+
+// First is like Do for the values holding resources, e.g. rows or streams: it also returns the index of
+// the winner and the cancel of its context, which must be called once the value isn't used anymore, and
+// the values of the losers that succeed later are given to release, which can be nil. The errors are named
+// by the kind and the index of their function, e.g. "replica 1"
+func First[T any](ctx context.Context, kind string, release func(T), fns ...func(ctx context.Context) (T, error)) (T, int, context.CancelFunc, error) {
+	return first(ctx, kind, fns, release)
+}
+
+// first calls the functions simultaneously, each one with its own child of the context, and returns
+// the value of the first one to succeed, its index and the cancel of its context, which must be called
+// once the value isn't used anymore. The others are canceled as soon as the winner is known and their
+// values that succeed later are given to release, which can be nil. If all of them fail the errors are
+// aggregated like the ones of a failed race, each one named by the kind and the index of its function
+func first[T any](ctx context.Context, kind string, fns []func(ctx context.Context) (T, error), release func(T)) (T, int, context.CancelFunc, error) {
+	var zero T
+	if len(fns) == 0 {
+		return zero, -1, nil, ErrNoRequests
+	}
+
+	// the functions are awaited like the attempts of a contest, results is closed once they all returned
+	results := make(chan firstOutcome[T], len(fns))
+	cancels := make([]context.CancelFunc, len(fns))
+	var attempts sync.WaitGroup
+	for i, fn := range fns {
+		fnCtx, cancel := context.WithCancel(ctx)
+		cancels[i] = cancel
+		attempts.Add(1)
+		go func(index int, fn func(ctx context.Context) (T, error)) {
+			defer attempts.Done()
+			value, err := fn(fnCtx)
+			results <- firstOutcome[T]{index: index, value: value, err: err}
+		}(i, fn)
+	}
+	go func() {
+		attempts.Wait()
+		close(results)
+	}()
+
+	errs := make([]error, len(fns))
+	for o := range results {
+		if o.err != nil {
+			errs[o.index] = fmt.Errorf("%s %d: %w", kind, o.index, o.err)
+			cancels[o.index]()
+			continue
+		}
+
+		for i, cancel := range cancels {
+			if i != o.index {
+				cancel()
+			}
+		}
+		go releaseLosers(results, release)
+		return o.value, o.index, cancels[o.index], nil
+	}
+
+	return zero, -1, nil, failed(errs...)
+}
+
+// releaseLosers gives the values of the functions still running to release once they succeed
+func releaseLosers[T any](results <-chan firstOutcome[T], release func(T)) {
+	for o := range results {
+		if o.err == nil && release != nil {
+			release(o.value)
+		}
+	}
+}
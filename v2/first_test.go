@@ -0,0 +1,49 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+func TestDo(t *testing.T) {
+	canceled := make(chan struct{})
+	slow := func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(canceled)
+		return 0, ctx.Err()
+	}
+	failing := func(ctx context.Context) (int, error) {
+		return 0, errors.New("failed")
+	}
+	fast := func(ctx context.Context) (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 42, nil
+	}
+
+	value, err := Do(context.Background(), slow, failing, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 42 {
+		t.Fatalf("Expected the value of the fast function, got %d", value)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the slow function to be canceled")
+	}
+
+	_, err = Do(context.Background(), failing, failing)
+	var merr *multierror.Error
+	if !errors.As(err, &merr) || len(merr.Errors) != 2 {
+		t.Fatalf("Expected the errors of all the functions, got %v", err)
+	}
+
+	if _, err := Do[int](context.Background()); err != ErrNoRequests {
+		t.Fatalf("Expected ErrNoRequests, got %v", err)
+	}
+}
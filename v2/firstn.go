@@ -0,0 +1,68 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrInvalidN is the error of FirstN and Consensus when n isn't between 1 and the number of requests
+var ErrInvalidN = errors.New("race: n must be between 1 and the number of requests")
+
+// FirstN makes the requests simultaneously and returns the first n successful responses in the
+// order they arrived, e.g. for quorum reads needing 2 of 3 replicas, the others are canceled.
+// If too many of them fail to get n responses, the responses are closed and the errors of the
+// failed attempts are returned like the ones of a failed race
+func (race *Race) FirstN(n int, reqs ...*http.Request) ([]*http.Response, error) {
+	return race.FirstNContext(context.Background(), n, reqs...)
+}
+
+// FirstNContext is like FirstN but the requests are canceled when the context is done
+func (race *Race) FirstNContext(ctx context.Context, n int, reqs ...*http.Request) ([]*http.Response, error) {
+	reqs = race.order(reqs)
+	if len(reqs) == 0 {
+		return nil, ErrNoRequests
+	}
+	if n < 1 || n > len(reqs) {
+		return nil, ErrInvalidN
+	}
+	if err := race.checkIdempotent(reqs); err != nil {
+		return nil, err
+	}
+
+	c := race.newContestContext(ctx, race.client.Timeout)
+	defer c.finish()
+
+	c.startAll(reqs)
+
+	responses := make([]*http.Response, 0, n)
+	errs := make([]error, len(reqs))
+	failures := 0
+	for len(responses) < n {
+		o := <-c.results
+		if o.err == nil {
+			responses = append(responses, c.keep(o.index, o.res))
+			continue
+		}
+
+		errs[o.index] = o.err
+		failures++
+		if failures > len(reqs)-n {
+			for _, res := range responses {
+				res.Body.Close()
+			}
+			return nil, failedAttempts(reqs, errs)
+		}
+	}
+	return responses, nil
+}
+
+// FirstN makes the requests simultaneously and returns the first n successful responses
+func FirstN(n int, reqs ...*http.Request) ([]*http.Response, error) {
+	return New().FirstN(n, reqs...)
+}
+
+// FirstNContext is like FirstN but the requests are canceled when the context is done
+func FirstNContext(ctx context.Context, n int, reqs ...*http.Request) ([]*http.Response, error) {
+	return New().FirstNContext(ctx, n, reqs...)
+}
@@ -0,0 +1,51 @@
+package race
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFirstN(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	req1, _ := http.NewRequest("GET", fast.URL, nil)
+	req2, _ := http.NewRequest("GET", slow.URL, nil)
+	req3, _ := http.NewRequest("GET", fast.URL+"/other", nil)
+
+	start := time.Now()
+	responses, err := FirstN(2, req1, req2, req3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("Expected 2 responses, got %d", len(responses))
+	}
+	for _, res := range responses {
+		if res.Request.URL.Host != req1.URL.Host {
+			t.Fatalf("Expected the fast responses, got %s", res.Request.URL)
+		}
+		res.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Expected the slow request to be canceled, took %v", elapsed)
+	}
+
+	failing, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	if _, err := FirstN(2, req1, failing); err == nil {
+		t.Fatal("Expected the quorum to fail")
+	}
+	if _, err := FirstN(3, req1, req3); !errors.Is(err, ErrInvalidN) {
+		t.Fatalf("Expected n to be invalid, got %v", err)
+	}
+}
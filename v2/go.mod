@@ -0,0 +1,18 @@
+module github.com/mostafa-asg/race/v2
+
+go 1.20
+
+require (
+	github.com/hashicorp/go-multierror v1.1.1
+	golang.org/x/oauth2 v0.0.0-20210819190943-2bc19b11175f
+	golang.org/x/sync v0.10.0
+	golang.org/x/time v0.3.0
+)
+
+require (
+	github.com/golang/protobuf v1.4.2 // indirect
+	github.com/hashicorp/errwrap v1.0.0 // indirect
+	golang.org/x/net v0.0.0-20200822124328-c89045814202 // indirect
+	google.golang.org/appengine v1.6.6 // indirect
+	google.golang.org/protobuf v1.25.0 // indirect
+)
@@ -0,0 +1,95 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// Handle is a race started in the background, see Start
+type Handle struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.Mutex
+	result   Result
+	err      error
+	taken    bool
+	canceled bool
+}
+
+// Start starts the race of the requests in the background and returns at once,
+// so the caller can do other work and join the race later with Wait
+func (race *Race) Start(reqs ...*http.Request) *Handle {
+	return race.StartContext(context.Background(), reqs...)
+}
+
+// StartContext is like Start but the race is canceled when the context is done
+func (race *Race) StartContext(ctx context.Context, reqs ...*http.Request) *Handle {
+	ctx, cancel := context.WithCancel(ctx)
+	h := &Handle{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		result, err := race.betweenResult(ctx, reqs)
+
+		h.mu.Lock()
+		h.result, h.err = result, err
+		h.closeUntaken()
+		h.mu.Unlock()
+		close(h.done)
+	}()
+	return h
+}
+
+// Start starts the race of the requests in the background and returns at once
+func Start(reqs ...*http.Request) *Handle {
+	return New().Start(reqs...)
+}
+
+// StartContext is like Start but the race is canceled when the context is done
+func StartContext(ctx context.Context, reqs ...*http.Request) *Handle {
+	return New().StartContext(ctx, reqs...)
+}
+
+// Wait waits for the race to finish and returns the first answer like Between
+func (h *Handle) Wait() (*http.Response, error) {
+	result, err := h.WaitResult()
+	return result.Response, err
+}
+
+// WaitResult waits for the race to finish and returns its Result
+func (h *Handle) WaitResult() (Result, error) {
+	<-h.done
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.canceled && h.err == nil {
+		return Result{ID: h.result.ID, Err: context.Canceled}, context.Canceled
+	}
+	h.taken = true
+	return h.result, h.err
+}
+
+// Done returns a channel closed when the race is finished
+func (h *Handle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Cancel cancels the race without waiting for it and closes the response of the winner, it does
+// nothing once Wait returned the response, so Cancel can be deferred right after Start
+func (h *Handle) Cancel() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.taken {
+		return
+	}
+	h.cancel()
+	h.canceled = true
+	h.closeUntaken()
+}
+
+// closeUntaken closes the response of the winner of a canceled race that Wait didn't return
+func (h *Handle) closeUntaken() {
+	if h.canceled && !h.taken && h.err == nil && h.result.Response != nil {
+		h.result.Response.Body.Close()
+	}
+}
@@ -0,0 +1,69 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	h := New().Start(req)
+	defer h.Cancel()
+
+	select {
+	case <-h.Done():
+		t.Fatal("Expected the race to run in the background")
+	default:
+	}
+
+	res, err := h.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	select {
+	case <-h.Done():
+	default:
+		t.Fatal("Expected the race to be done after Wait")
+	}
+
+	// canceling after Wait keeps the response
+	h.Cancel()
+	if body, err := ioutil.ReadAll(res.Body); err != nil || string(body) != "ok" {
+		t.Fatalf("Expected the returned response to stay open, got %q %v", body, err)
+	}
+}
+
+func TestStartCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	h := New().Start(req)
+	h.Cancel()
+
+	select {
+	case <-h.Done():
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Expected the race to be canceled")
+	}
+	if _, err := h.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the race to be canceled, got %v", err)
+	}
+}
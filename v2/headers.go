@@ -0,0 +1,49 @@
+package race
+
+import "net/http"
+
+// DefaultTargetHeaders are the headers that tell which target answered, see WithHeaderPolicy
+var DefaultTargetHeaders = []string{"Set-Cookie", "Via", "Server", "X-Served-By", "X-Cache", "X-Cache-Hits", "X-Backend-Server"}
+
+// HeaderPolicy tells which headers of the winning response are kept, the names are case insensitive
+type HeaderPolicy struct {
+	// Allow keeps only the given headers, all of them are kept if it's empty
+	Allow []string
+	// Deny removes the given headers, e.g. DefaultTargetHeaders
+	Deny []string
+	// Set replaces the values of the given headers, e.g. a fixed Server
+	Set http.Header
+}
+
+// WithHeaderPolicy normalizes the headers of the winning response with the policy, so callers see
+// consistent responses whichever mirror answered, e.g. when they are cached or compared upstream.
+// It's applied as a Processor, the headers added by the processors appended later aren't affected
+func WithHeaderPolicy(policy HeaderPolicy) Option {
+	allow := make(map[string]bool, len(policy.Allow))
+	for _, name := range policy.Allow {
+		allow[http.CanonicalHeaderKey(name)] = true
+	}
+
+	return WithProcessor(func(res *http.Response, index int) (*http.Response, error) {
+		if res.Header == nil {
+			res.Header = make(http.Header)
+		}
+		if len(allow) > 0 {
+			for name := range res.Header {
+				if !allow[http.CanonicalHeaderKey(name)] {
+					delete(res.Header, name)
+				}
+			}
+		}
+		for _, name := range policy.Deny {
+			res.Header.Del(name)
+		}
+		for name, values := range policy.Set {
+			res.Header.Del(name)
+			for _, value := range values {
+				res.Header.Add(name, value)
+			}
+		}
+		return res, nil
+	})
+}
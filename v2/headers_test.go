@@ -0,0 +1,41 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeaderPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "mirror-a")
+		w.Header().Set("Via", "1.1 cache-a")
+		w.Header().Set("Set-Cookie", "affinity=a")
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Internal", "secret")
+	}))
+	defer server.Close()
+
+	get := func(policy HeaderPolicy) http.Header {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		res, err := New(WithHeaderPolicy(policy)).Between(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res.Header
+	}
+
+	header := get(HeaderPolicy{Deny: DefaultTargetHeaders, Set: http.Header{"Server": {"race"}}})
+	if header.Get("Via") != "" || header.Get("Set-Cookie") != "" || header.Get("Server") != "race" {
+		t.Fatalf("Expected the target headers to be normalized, got %v", header)
+	}
+	if header.Get("Content-Type") != "text/plain" || header.Get("X-Internal") != "secret" {
+		t.Fatalf("Expected the other headers to be kept, got %v", header)
+	}
+
+	header = get(HeaderPolicy{Allow: []string{"content-type"}})
+	if len(header) != 1 || header.Get("Content-Type") != "text/plain" {
+		t.Fatalf("Expected only the allowed header, got %v", header)
+	}
+}
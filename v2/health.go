@@ -0,0 +1,85 @@
+package race
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// VersionHeader is the response header CheckAll reads the version of a target from
+const VersionHeader = "X-Build-Version"
+
+// Status is the health of a target reported by CheckAll
+type Status struct {
+	Target     Target
+	Latency    time.Duration
+	StatusCode int
+	// Version is the value of VersionHeader
+	Version string
+	Header  http.Header
+	Err     error
+}
+
+// Healthy reports whether the target answered with a 2xx status code
+func (status Status) Healthy() bool {
+	return status.Err == nil && status.StatusCode >= 200 && status.StatusCode < 300
+}
+
+// CheckAll sends a GET request for the health path to every target concurrently
+// and returns their status in the order of the targets, the requests are made by All
+func (race *Race) CheckAll(ctx context.Context, targets []Target, path string) []Status {
+	statuses := make([]Status, len(targets))
+
+	var reqs []*http.Request
+	var checked []*Status
+	for i, target := range targets {
+		status := &statuses[i]
+		status.Target = target
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		if err == nil {
+			req, err = target.Clone(req)
+		}
+		if err != nil {
+			status.Err = err
+			continue
+		}
+		reqs = append(reqs, req)
+		checked = append(checked, status)
+	}
+	if len(reqs) == 0 {
+		return statuses
+	}
+
+	results, err := race.All(ctx, reqs...)
+	if err != nil {
+		for _, status := range checked {
+			status.Err = err
+		}
+		return statuses
+	}
+
+	for i, result := range results {
+		status := checked[i]
+		status.Latency = result.Elapsed
+		if result.Err != nil {
+			status.Err = result.Err
+			continue
+		}
+		res := result.Response
+		io.Copy(ioutil.Discard, res.Body)
+		res.Body.Close()
+
+		status.StatusCode = res.StatusCode
+		status.Version = res.Header.Get(VersionHeader)
+		status.Header = res.Header
+	}
+	return statuses
+}
+
+// CheckAll sends a GET request for the health path to every target concurrently
+// and returns their status in the order of the targets
+func CheckAll(ctx context.Context, targets []Target, path string) []Status {
+	return New().CheckAll(ctx, targets, path)
+}
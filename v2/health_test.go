@@ -0,0 +1,40 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckAll(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set(VersionHeader, "1.2.3")
+	}))
+	defer healthy.Close()
+
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthy.Close()
+
+	targets := []Target{{URL: healthy.URL}, {URL: unhealthy.URL}, {URL: unresolvableDomain}}
+	statuses := CheckAll(context.Background(), targets, "/healthz")
+
+	if len(statuses) != 3 {
+		t.Fatalf("Expected 3 statuses, got %d", len(statuses))
+	}
+	if !statuses[0].Healthy() || statuses[0].Version != "1.2.3" || statuses[0].Latency <= 0 {
+		t.Fatalf("Expected the first target to be healthy with version 1.2.3, got %+v", statuses[0])
+	}
+	if statuses[1].Healthy() || statuses[1].StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected the second target to be unhealthy, got %+v", statuses[1])
+	}
+	if statuses[2].Err == nil || statuses[2].Target.URL != unresolvableDomain {
+		t.Fatalf("Expected the third target to fail, got %+v", statuses[2])
+	}
+}
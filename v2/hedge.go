@@ -0,0 +1,59 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Hedged starts the first request and each backup after its own delay since the start of the race,
+// e.g. 100ms and 300ms for two backups, so capacity is added gradually instead of all at once.
+// A backup starts earlier if all the started requests failed, and the backups without a delay in
+// the schedule start with the last one. If the Race has a Strategy it decides the order of the requests
+func (race *Race) Hedged(first *http.Request, schedule []time.Duration, backups ...*http.Request) (*http.Response, error) {
+	return race.HedgedContext(context.Background(), first, schedule, backups...)
+}
+
+// HedgedContext is like Hedged but the race is canceled when the context is done
+func (race *Race) HedgedContext(ctx context.Context, first *http.Request, schedule []time.Duration, backups ...*http.Request) (*http.Response, error) {
+	reqs := race.order(append([]*http.Request{first}, backups...))
+	if len(reqs) == 0 {
+		return nil, ErrNoRequests
+	}
+
+	c := race.newContestContext(ctx, 0)
+	defer c.finish()
+
+	return c.run(hedgeTiers(reqs, schedule))
+}
+
+// Hedged starts the first request and each backup after its own delay since the start of the race
+func Hedged(first *http.Request, schedule []time.Duration, backups ...*http.Request) (*http.Response, error) {
+	return New().Hedged(first, schedule, backups...)
+}
+
+// HedgedContext is like Hedged but the race is canceled when the context is done
+func HedgedContext(ctx context.Context, first *http.Request, schedule []time.Duration, backups ...*http.Request) (*http.Response, error) {
+	return New().HedgedContext(ctx, first, schedule, backups...)
+}
+
+// hedgeTiers returns a tier per request whose timeout is the delay until the next one starts,
+// the requests after the end of the schedule are in the last tier
+func hedgeTiers(reqs []*http.Request, schedule []time.Duration) []Tier {
+	if len(schedule) > len(reqs)-1 {
+		schedule = schedule[:len(reqs)-1]
+	}
+
+	tiers := make([]Tier, 0, len(schedule)+1)
+	var previous time.Duration
+	for i, delay := range schedule {
+		timeout := delay - previous
+		if timeout < 0 {
+			timeout = 0
+		} else {
+			previous = delay
+		}
+		tiers = append(tiers, Tier{Requests: reqs[i : i+1], Timeout: timeout})
+	}
+	return append(tiers, Tier{Requests: reqs[len(schedule):]})
+}
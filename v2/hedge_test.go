@@ -0,0 +1,70 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHedged(t *testing.T) {
+	var mu sync.Mutex
+	started := make(map[string]time.Duration)
+	start := time.Now()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		started[r.URL.Path] = time.Since(start)
+		mu.Unlock()
+		if r.URL.Path == "/third" {
+			return
+		}
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	first, _ := http.NewRequest("GET", server.URL+"/first", nil)
+	second, _ := http.NewRequest("GET", server.URL+"/second", nil)
+	third, _ := http.NewRequest("GET", server.URL+"/third", nil)
+
+	res, err := Hedged(first, []time.Duration{50 * time.Millisecond, 150 * time.Millisecond}, second, third)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if started["/second"] < 50*time.Millisecond || started["/second"] > 140*time.Millisecond {
+		t.Fatalf("Expected the second request to start after 50ms, started after %s", started["/second"])
+	}
+	if started["/third"] < 150*time.Millisecond {
+		t.Fatalf("Expected the third request to start after 150ms, started after %s", started["/third"])
+	}
+}
+
+func TestHedgeTiers(t *testing.T) {
+	reqs := make([]*http.Request, 4)
+	for i := range reqs {
+		reqs[i], _ = http.NewRequest("GET", "http://localhost", nil)
+	}
+
+	tiers := hedgeTiers(reqs, []time.Duration{100 * time.Millisecond, 50 * time.Millisecond})
+	if len(tiers) != 3 {
+		t.Fatalf("Expected 3 tiers, got %d", len(tiers))
+	}
+	if tiers[0].Timeout != 100*time.Millisecond || tiers[1].Timeout != 0 {
+		t.Fatalf("Expected the delays relative to the previous request, got %s and %s", tiers[0].Timeout, tiers[1].Timeout)
+	}
+	if len(tiers[2].Requests) != 2 {
+		t.Fatal("Expected the requests after the schedule to start together")
+	}
+
+	if tiers := hedgeTiers(reqs[:1], []time.Duration{time.Second}); len(tiers) != 1 || len(tiers[0].Requests) != 1 {
+		t.Fatal("Expected a single tier without backups")
+	}
+}
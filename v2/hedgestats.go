@@ -0,0 +1,75 @@
+package race
+
+import (
+	"sync"
+	"time"
+)
+
+// HedgeStats tells how useful the hedges of the races were, see WithHedgeStats
+type HedgeStats struct {
+	// Won is the number of races that were won
+	Won int
+	// Hedged is the number of the races won after a hedge started
+	Hedged int
+	// Useless is the number of the hedged races the primary won anyway
+	Useless int
+	// SuggestedDelay is an advisory delay of the hedges, the 90th percentile of the latency of the
+	// primaries that won the last races, so the hedges start only for the slowest primaries.
+	// It's zero until a primary won
+	SuggestedDelay time.Duration
+}
+
+type hedgeStats struct {
+	mu        sync.Mutex
+	won       int
+	hedged    int
+	useless   int
+	primaries cohort
+}
+
+// WithHedgeStats records how often the hedges start but the primary still wins, so the delay of the
+// hedges can be tuned, see Hedges
+func WithHedgeStats() Option {
+	return func(race *Race) {
+		race.hedgeStats = &hedgeStats{}
+	}
+}
+
+// Hedges returns the stats of the hedges, they are empty without WithHedgeStats
+func (race *Race) Hedges() HedgeStats {
+	h := race.hedgeStats
+	if h == nil {
+		return HedgeStats{}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := HedgeStats{Won: h.won, Hedged: h.hedged, Useless: h.useless}
+	if h.primaries.races > 0 {
+		stats.SuggestedDelay = h.primaries.stats().P90
+	}
+	return stats
+}
+
+// observe records a race won after the given time, hedged tells whether a hedge started
+// and primary whether a primary won
+func (h *hedgeStats) observe(hedged, primary bool, elapsed time.Duration) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.won++
+	if hedged {
+		h.hedged++
+		if primary {
+			h.useless++
+		}
+	}
+	if primary {
+		h.primaries.add(elapsed, nil)
+	}
+}
@@ -0,0 +1,81 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHedgeStats(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+	}))
+	defer primary.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	r := New(WithHedgeStats())
+	defer r.Close()
+
+	if stats := r.Hedges(); stats != (HedgeStats{}) {
+		t.Fatalf("Expected no stats before a race, got %+v", stats)
+	}
+
+	race := func(hedge string, delay time.Duration) {
+		req1, _ := http.NewRequest("GET", primary.URL, nil)
+		req2, _ := http.NewRequest("GET", hedge, nil)
+		res, err := r.FirstThenStart(req1, delay, req2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	race(slow.URL, 5*time.Millisecond)
+	race(slow.URL, 200*time.Millisecond)
+	race(fast.URL, 5*time.Millisecond)
+
+	stats := r.Hedges()
+	if stats.Won != 3 || stats.Hedged != 2 || stats.Useless != 1 {
+		t.Fatalf("Expected 3 races, 2 hedged and 1 useless, got %+v", stats)
+	}
+	if stats.SuggestedDelay < 30*time.Millisecond {
+		t.Fatalf("Expected the delay to cover the latency of the primary, got %v", stats.SuggestedDelay)
+	}
+}
+
+func TestHedgeStats_Primaries(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	r := New(WithHedgeStats())
+	defer r.Close()
+
+	req1, _ := http.NewRequest("GET", slow.URL, nil)
+	req2, _ := http.NewRequest("GET", fast.URL, nil)
+	fallback, _ := http.NewRequest("GET", fast.URL, nil)
+	res, err := r.PrimariesThenStart([]*http.Request{req1, req2}, time.Second, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	stats := r.Hedges()
+	if stats.Won != 1 || stats.Hedged != 0 || stats.SuggestedDelay == 0 {
+		t.Fatalf("Expected the second primary to win without a hedge, got %+v", stats)
+	}
+}
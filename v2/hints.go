@@ -0,0 +1,82 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"sync"
+)
+
+// WithEarlyHints calls fn with the 103 Early Hints the winner of a race sent before its response,
+// e.g. to start preloading the linked resources, before the response is returned.
+// The informational responses never win a race, only the final response does
+func WithEarlyHints(fn func(winner *http.Response, hints []http.Header)) Option {
+	return func(race *Race) {
+		race.earlyHints = fn
+	}
+}
+
+// traceHints records the Early Hints of the attempt in the contest once its response arrives
+func (c *contest) traceHints(req *http.Request) (*http.Request, func(res *http.Response)) {
+	if !c.race.tracingHints() {
+		return req, func(*http.Response) {}
+	}
+
+	var mu sync.Mutex
+	var hints []http.Header
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				mu.Lock()
+				hints = append(hints, http.Header(header).Clone())
+				mu.Unlock()
+			}
+			return nil
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	return req, func(res *http.Response) {
+		mu.Lock()
+		defer mu.Unlock()
+		if res == nil || len(hints) == 0 {
+			return
+		}
+
+		c.mu.Lock()
+		if c.hints == nil {
+			c.hints = make(map[*http.Response][]http.Header)
+		}
+		c.hints[res] = hints
+		c.mu.Unlock()
+	}
+}
+
+// tracingHints reports whether the Early Hints of the attempts are recorded
+func (race *Race) tracingHints() bool {
+	return race.earlyHints != nil || race.preloadTTL > 0
+}
+
+// reportHints calls the hook with the Early Hints of the winner of the target and preloads their links
+func (c *contest) reportHints(target *http.Request, res *http.Response) {
+	if !c.race.tracingHints() {
+		return
+	}
+
+	c.mu.Lock()
+	hints := c.hints[res]
+	c.hints = nil
+	c.mu.Unlock()
+	if len(hints) == 0 {
+		return
+	}
+	base := target.URL
+	if res.Request != nil {
+		// the links are relative to the redirected URL
+		base = res.Request.URL
+	}
+	c.race.preload(base, hints)
+	if c.race.earlyHints != nil {
+		c.race.earlyHints(res, hints)
+	}
+}
@@ -0,0 +1,60 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEarlyHints(t *testing.T) {
+	hintingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		w.WriteHeader(http.StatusEarlyHints)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("hinting"))
+	}))
+	defer hintingServer.Close()
+
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer fastServer.Close()
+
+	reported := make(chan []http.Header, 1)
+	r := New(WithEarlyHints(func(winner *http.Response, hints []http.Header) {
+		reported <- hints
+	}))
+	defer r.Close()
+
+	req1, _ := http.NewRequest("GET", hintingServer.URL, nil)
+	req2, _ := http.NewRequest("GET", fastServer.URL, nil)
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.Request.URL.Host != req2.URL.Host {
+		t.Fatal("Expected the Early Hints not to win the race")
+	}
+	select {
+	case <-reported:
+		t.Fatal("Expected only the hints of the winner to be reported")
+	default:
+	}
+
+	req, _ := http.NewRequest("GET", hintingServer.URL, nil)
+	res, err = r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	select {
+	case hints := <-reported:
+		if len(hints) != 1 || hints[0].Get("Link") != "</style.css>; rel=preload; as=style" {
+			t.Fatalf("Unexpected hints %v", hints)
+		}
+	default:
+		t.Fatal("Expected the hints of the winner to be reported")
+	}
+}
@@ -0,0 +1,71 @@
+package race
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"net/http"
+	"time"
+)
+
+// DefaultIDHeader is the header used by WithIDHeader when no name is given
+const DefaultIDHeader = "X-Race-ID"
+
+// crockford is the base32 alphabet of ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+type idKey struct{}
+
+// WithIDHeader sends the ID of the race with the requests of its attempts, so the logs of the
+// backends can be joined with the client's. The header defaults to DefaultIDHeader
+func WithIDHeader(header string) Option {
+	if header == "" {
+		header = DefaultIDHeader
+	}
+
+	return func(race *Race) {
+		race.idHeader = header
+	}
+}
+
+// ID returns the ID of the race the context belongs to, the context of the requests of the
+// attempts and of the responses, e.g. res.Request.Context(). Every race gets a ULID, which is
+// also in its Record
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
+}
+
+// newID returns a new ULID
+func newID() string {
+	var data [16]byte
+	binary.BigEndian.PutUint64(data[:8], uint64(time.Now().UnixMilli())<<16)
+	rand.Read(data[6:])
+
+	// 26 characters of 5 bits, the first one only has 3
+	var id [26]byte
+	hi := binary.BigEndian.Uint64(data[:8])
+	lo := binary.BigEndian.Uint64(data[8:])
+	for i := 25; i >= 0; i-- {
+		id[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(id[:])
+}
+
+// withID adds the ID header and the idempotency key of the race to the request of an attempt
+func (race *Race) withID(req *http.Request) *http.Request {
+	if race.idHeader == "" && race.idempotencyHeader == "" {
+		return req
+	}
+
+	req = req.Clone(req.Context())
+	if race.idHeader != "" {
+		req.Header.Set(race.idHeader, ID(req.Context()))
+	}
+	if race.idempotencyHeader != "" && req.Header.Get(race.idempotencyHeader) == "" {
+		req.Header.Set(race.idempotencyHeader, ID(req.Context()))
+	}
+	return req
+}
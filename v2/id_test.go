@@ -0,0 +1,51 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRaceID(t *testing.T) {
+	ids := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids <- r.Header.Get(DefaultIDHeader)
+	}))
+	defer server.Close()
+
+	r := New(WithIDHeader(""), WithFlightRecorder(1))
+	req1, _ := http.NewRequest("GET", server.URL, nil)
+	req2, _ := http.NewRequest("GET", server.URL, nil)
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	r.Close()
+
+	id := ID(res.Request.Context())
+	if len(id) != 26 || strings.Trim(id, crockford) != "" {
+		t.Fatalf("Expected a ULID, got %q", id)
+	}
+	if sent := <-ids; sent != id {
+		t.Fatalf("Expected the ID %s to be sent, got %s", id, sent)
+	}
+	if records := r.FlightRecords(); records[0].ID != id {
+		t.Fatalf("Expected the ID %s to be recorded, got %s", id, records[0].ID)
+	}
+	if req1.Header.Get(DefaultIDHeader) != "" {
+		t.Fatal("Expected the original request not to be modified")
+	}
+}
+
+func TestNewIDIsSorted(t *testing.T) {
+	previous := newID()
+	for i := 0; i < 100; i++ {
+		id := newID()
+		if id[:10] < previous[:10] {
+			t.Fatalf("Expected the IDs to be sorted by time, %s < %s", id, previous)
+		}
+		previous = id
+	}
+}
@@ -0,0 +1,67 @@
+package race
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DefaultIdempotencyHeader is the header used by WithIdempotencyKey when no name is given
+const DefaultIdempotencyHeader = "Idempotency-Key"
+
+// ErrNonIdempotent is the error of the races of several requests with a non-idempotent method,
+// e.g. POST, which could be applied more than once, see AllowNonIdempotent
+var ErrNonIdempotent = errors.New("race: non-idempotent request")
+
+// AllowNonIdempotent lets the Race send several requests with a non-idempotent method, e.g. when
+// the targets are read-only despite the method or they deduplicate the requests. Without it such
+// races fail with ErrNonIdempotent before any request is sent, unless the requests have an
+// Idempotency-Key header. A single request is always allowed
+func AllowNonIdempotent() Option {
+	return func(race *Race) {
+		race.allowNonIdempotent = true
+	}
+}
+
+// WithIdempotencyKey sends the same idempotency key with all the attempts of a race, so the targets
+// supporting idempotency keys can deduplicate hedged writes, and allows racing non-idempotent requests.
+// The key is the ID of the race, the requests that already have the header keep theirs.
+// The header defaults to DefaultIdempotencyHeader
+func WithIdempotencyKey(header string) Option {
+	if header == "" {
+		header = DefaultIdempotencyHeader
+	}
+
+	return func(race *Race) {
+		race.idempotencyHeader = header
+	}
+}
+
+// checkIdempotent returns an error if the requests can't be raced safely
+func (race *Race) checkIdempotent(reqs []*http.Request) error {
+	if race.allowNonIdempotent || race.idempotencyHeader != "" || len(reqs) < 2 {
+		return nil
+	}
+	for _, req := range reqs {
+		if !race.isIdempotent(req) {
+			return fmt.Errorf("%w: %s %s", ErrNonIdempotent, req.Method, req.URL.Redacted())
+		}
+	}
+	return nil
+}
+
+// isIdempotent reports whether the request can be sent more than once, i.e. its method is idempotent
+// or it has the idempotency key header of the Race, DefaultIdempotencyHeader if it has none
+func (race *Race) isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	header := race.idempotencyHeader
+	if header == "" {
+		header = DefaultIdempotencyHeader
+	}
+	_, ok := req.Header[http.CanonicalHeaderKey(header)]
+	return ok
+}
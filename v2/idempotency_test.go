@@ -0,0 +1,108 @@
+package race
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNonIdempotent(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	charge := func() []*http.Request {
+		req1, _ := http.NewRequest("POST", server.URL+"/charge", nil)
+		req2, _ := http.NewRequest("POST", server.URL+"/charge", nil)
+		return []*http.Request{req1, req2}
+	}
+
+	if _, err := Between(charge()...); !errors.Is(err, ErrNonIdempotent) {
+		t.Fatalf("Expected ErrNonIdempotent, got %v", err)
+	}
+	if _, err := New().DoubleRead(time.Second, charge()...); !errors.Is(err, ErrNonIdempotent) {
+		t.Fatalf("Expected ErrNonIdempotent from DoubleRead, got %v", err)
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Fatal("Expected no request to be sent")
+	}
+
+	single, _ := http.NewRequest("POST", server.URL+"/charge", nil)
+	res, err := Between(single)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	keyed := charge()
+	for _, req := range keyed {
+		req.Header.Set("Idempotency-Key", "charge-1")
+	}
+	res, err = Between(keyed...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	r := New(AllowNonIdempotent())
+	defer r.Close()
+	res, err = r.Between(charge()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	keys := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.Header.Get("X-Request-Key")
+		// both attempts arrive before the winner answers
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	r := New(WithIdempotencyKey("X-Request-Key"))
+	defer r.Close()
+
+	req1, _ := http.NewRequest("POST", server.URL, nil)
+	req2, _ := http.NewRequest("POST", server.URL, nil)
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	first, second := <-keys, <-keys
+	if first == "" || first != second {
+		t.Fatalf("Expected the same key for the attempts, got %q and %q", first, second)
+	}
+
+	req1, _ = http.NewRequest("POST", server.URL, nil)
+	req1.Header.Set("X-Request-Key", "given")
+	res, err = r.Between(req1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if key := <-keys; key != "given" {
+		t.Fatalf("Expected the given key to be kept, got %q", key)
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://placeholder/charge", nil)
+	req.Header.Set("X-Request-Key", "charge-1")
+
+	if New().isIdempotent(req) {
+		t.Fatal("Expected another header than Idempotency-Key not to make the request idempotent")
+	}
+	if !New(WithIdempotencyKey("X-Request-Key")).isIdempotent(req) {
+		t.Fatal("Expected the idempotency key header of the Race to make the request idempotent")
+	}
+}
@@ -0,0 +1,48 @@
+//go:build go1.23
+
+package race
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// Responses makes the requests simultaneously and yields the responses and the errors, as
+// *AttemptError, in the order they arrive. Breaking out of the loop cancels the requests that
+// are still running, the yielded responses outlive the loop and must be closed
+func (race *Race) Responses(ctx context.Context, reqs ...*http.Request) iter.Seq2[*http.Response, error] {
+	return func(yield func(*http.Response, error) bool) {
+		reqs := race.order(reqs)
+		if len(reqs) == 0 {
+			yield(nil, ErrNoRequests)
+			return
+		}
+		if err := race.checkIdempotent(reqs); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		c := race.newContestContext(ctx, race.client.Timeout)
+		defer c.finish()
+
+		c.startAll(reqs)
+		for range reqs {
+			o := <-c.results
+			if o.err != nil {
+				if !yield(nil, &AttemptError{Index: o.index, Request: reqs[o.index], Err: o.err}) {
+					return
+				}
+				continue
+			}
+			if !yield(c.keep(o.index, o.res), nil) {
+				return
+			}
+		}
+	}
+}
+
+// Responses makes the requests simultaneously and yields the responses and the errors as they arrive
+func Responses(ctx context.Context, reqs ...*http.Request) iter.Seq2[*http.Response, error] {
+	return New().Responses(ctx, reqs...)
+}
@@ -0,0 +1,81 @@
+//go:build go1.23
+
+package race
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponses(t *testing.T) {
+	streaming := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chunk := bytes.Repeat([]byte("x"), 64<<10)
+		for i := 0; i < 16; i++ {
+			w.Write(chunk)
+			w.(http.Flusher).Flush()
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+	defer streaming.Close()
+
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer second.Close()
+
+	canceled := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second):
+		case <-r.Context().Done():
+			close(canceled)
+		}
+	}))
+	defer slow.Close()
+
+	req1, _ := http.NewRequest("GET", streaming.URL, nil)
+	req2, _ := http.NewRequest("GET", second.URL, nil)
+	req3, _ := http.NewRequest("GET", slow.URL, nil)
+
+	var responses []*http.Response
+	for res, err := range Responses(context.Background(), req1, req2, req3) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, res)
+		if len(responses) == 2 {
+			break
+		}
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected breaking out of the loop to cancel the slow request")
+	}
+
+	// the yielded responses outlive the loop
+	for _, res := range responses {
+		if _, err := ioutil.ReadAll(res.Body); err != nil {
+			t.Fatalf("Expected the body of %s to be readable: %v", res.Request.URL, err)
+		}
+		res.Body.Close()
+	}
+}
+
+func TestResponsesErrors(t *testing.T) {
+	req, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	for res, err := range Responses(context.Background(), req) {
+		if res != nil {
+			t.Fatal("Expected no response")
+		}
+		if attempt, ok := err.(*AttemptError); !ok || attempt.Index != 0 {
+			t.Fatalf("Expected an AttemptError, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package race
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// ewmaWeight is the weight of a new sample in the moving average
+	ewmaWeight = 0.3
+	// failurePenalty is the latency recorded for failed attempts
+	failurePenalty = 30 * time.Second
+)
+
+// latencies keeps exponentially weighted moving averages of the latency of the successful attempts
+// and of the error rate of every host
+type latencies struct {
+	mu  sync.Mutex
+	avg map[string]time.Duration
+	// errorRate is between 0 and 1, the hosts that were observed have one
+	errorRate map[string]float64
+}
+
+func newLatencies() *latencies {
+	return &latencies{
+		avg:       make(map[string]time.Duration),
+		errorRate: make(map[string]float64),
+	}
+}
+
+// observe records an attempt made to the host, canceled losers are at least as slow as the time they ran
+func (l *latencies) observe(host string, elapsed time.Duration, err error) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	avg, ok := l.avg[host]
+	failure := 0.0
+	switch {
+	case canceled(err):
+		if elapsed <= avg {
+			return
+		}
+	case err != nil:
+		failure = 1
+	}
+
+	if rate, seen := l.errorRate[host]; seen {
+		l.errorRate[host] = ewmaWeight*failure + (1-ewmaWeight)*rate
+	} else {
+		l.errorRate[host] = failure
+	}
+	switch {
+	case failure == 1:
+	case !ok:
+		l.avg[host] = elapsed
+	default:
+		l.avg[host] = time.Duration(ewmaWeight*float64(elapsed) + (1-ewmaWeight)*float64(avg))
+	}
+}
+
+// score returns the expected cost of an attempt to the host, the latency plus the error rate of
+// the cost of a failure, zero if it has never been observed. The caller holds the lock
+func (l *latencies) score(host string) time.Duration {
+	return l.avg[host] + time.Duration(l.errorRate[host]*float64(failurePenalty))
+}
+
+// sorted returns the targets from the best to the worst score,
+// the targets that have never been observed come first so they get a chance
+func (l *latencies) sorted(targets []*http.Request) []*http.Request {
+	sorted := make([]*http.Request, len(targets))
+	copy(sorted, targets)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return l.score(sorted[i].URL.Host) < l.score(sorted[j].URL.Host)
+	})
+
+	return sorted
+}
@@ -0,0 +1,42 @@
+package race
+
+import "strings"
+
+// link is a link of a Link header
+type link struct {
+	target string
+	rels   []string
+}
+
+// parseLinks parses the values of Link headers
+func parseLinks(values []string) []link {
+	var links []link
+	for _, header := range values {
+		for _, value := range strings.Split(header, ",") {
+			parts := strings.Split(value, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+
+			l := link{target: target[1 : len(target)-1]}
+			for _, param := range parts[1:] {
+				param = strings.TrimSpace(param)
+				if strings.HasPrefix(strings.ToLower(param), "rel=") {
+					l.rels = append(l.rels, strings.Fields(strings.ToLower(strings.Trim(param[len("rel="):], `"`)))...)
+				}
+			}
+			links = append(links, l)
+		}
+	}
+	return links
+}
+
+func (l link) is(rel string) bool {
+	for _, r := range l.rels {
+		if r == rel {
+			return true
+		}
+	}
+	return false
+}
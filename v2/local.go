@@ -0,0 +1,39 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path/filepath"
+)
+
+// BetweenLocal races reading the file at the path, e.g. from a warm local mirror or cache, against the
+// requests over the network and returns the Result of the first valid answer. The file is answered like
+// by http.FileServer and only wins when it exists, the responses of the requests are checked by the
+// validators of the Race. When the file wins the Request of the Result has the file scheme
+func (race *Race) BetweenLocal(ctx context.Context, path string, reqs ...*http.Request) (Result, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return Result{Err: err}, err
+	}
+	dir, name := filepath.Split(path)
+	local, err := http.NewRequestWithContext(ctx, http.MethodGet, (&url.URL{Scheme: "file", Path: "/" + name}).String(), nil)
+	if err != nil {
+		return Result{Err: err}, err
+	}
+
+	attempts := []Attempt{{
+		Request:   local,
+		Client:    &http.Client{Transport: http.NewFileTransport(http.Dir(dir))},
+		Validator: SuccessfulStatus,
+	}}
+	for _, req := range reqs {
+		attempts = append(attempts, Attempt{Request: req})
+	}
+	return race.Run(ctx, attempts)
+}
+
+// BetweenLocal races reading the file at the path against the requests over the network
+func BetweenLocal(ctx context.Context, path string, reqs ...*http.Request) (Result, error) {
+	return New().BetweenLocal(ctx, path, reqs...)
+}
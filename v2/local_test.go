@@ -0,0 +1,45 @@
+package race
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBetweenLocal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("remote"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mirrored file.txt")
+	if err := os.WriteFile(path, []byte("local"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path string
+		body string
+	}{
+		{path, "local"},
+		{filepath.Join(dir, "missing.txt"), "remote"},
+	} {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		result, err := BetweenLocal(context.Background(), test.path, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(result.Response.Body)
+		result.Response.Body.Close()
+		if string(body) != test.body {
+			t.Fatalf("Expected the %s content, got %q", test.body, body)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package race
+
+import (
+	"net/http"
+	"time"
+)
+
+// LoserHeaders is the metadata of a loser of a race, see WithLoserHeaders
+type LoserHeaders struct {
+	Request *http.Request
+	// StatusCode, Header and ContentLength are empty if the loser failed with Err
+	StatusCode    int
+	Header        http.Header
+	ContentLength int64
+	Err           error
+	// Elapsed is the time until the response headers of the loser arrived or it failed
+	Elapsed time.Duration
+}
+
+// WithLoserHeaders lets the losers of a race run for up to the budget after the winner is returned, like
+// WithLoserCompletion, and calls fn with the status and the headers of every one of them, e.g. to compare
+// their version headers with the winner's. Their bodies are closed without being read, so the signal costs
+// almost no bandwidth. The losers that failed before the winner arrived, or that are still running when
+// the budget elapses, are reported with their error
+func WithLoserHeaders(budget time.Duration, fn func(LoserHeaders)) Option {
+	return func(race *Race) {
+		race.loserHeaders = fn
+		if budget > race.loserBudget {
+			race.loserBudget = budget
+		}
+	}
+}
+
+// recordFailure keeps the failure of an attempt until the winner of the race reports it as a loser,
+// the failures of the races without a winner aren't reported. It reports whether it was kept
+func (c *contest) recordFailure(index int, req *http.Request, elapsed time.Duration, err error) bool {
+	if c.race.loserHeaders == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reported {
+		return false
+	}
+	if c.failed == nil {
+		c.failed = make(map[int]LoserHeaders)
+	}
+	c.failed[index] = LoserHeaders{Request: req, Err: err, Elapsed: elapsed}
+	return true
+}
+
+// unreported takes the kept failure of the attempt, it reports whether the winner didn't report it
+func (c *contest) unreported(index int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, pending := c.failed[index]
+	delete(c.failed, index)
+	return pending
+}
+
+// reportFailedLosers gives the losers that failed before the winner arrived to the callback of WithLoserHeaders
+func (c *contest) reportFailedLosers() {
+	if c.race.loserHeaders == nil {
+		return
+	}
+
+	c.mu.Lock()
+	failed := c.failed
+	c.failed = nil
+	c.reported = true
+	c.mu.Unlock()
+
+	for _, loser := range failed {
+		c.race.loserHeaders(loser)
+	}
+}
+
+// reportLoserHeaders gives the metadata of a loser that finished after the race to the callback of WithLoserHeaders
+func (c *contest) reportLoserHeaders(req *http.Request, elapsed time.Duration, res *http.Response, err error) {
+	if c.race.loserHeaders == nil {
+		return
+	}
+
+	loser := LoserHeaders{Request: req, Err: err, Elapsed: elapsed}
+	if res != nil {
+		loser.StatusCode = res.StatusCode
+		loser.Header = res.Header
+		loser.ContentLength = res.ContentLength
+	}
+	c.race.loserHeaders(loser)
+}
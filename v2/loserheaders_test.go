@@ -0,0 +1,94 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoserHeaders(t *testing.T) {
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(VersionHeader, "2")
+	}))
+	defer fast.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		w.Header().Set(VersionHeader, "1")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write(make([]byte, 1<<20))
+	}))
+	defer slow.Close()
+
+	losers := make(chan LoserHeaders, 1)
+	r := New(WithLoserHeaders(time.Second, func(loser LoserHeaders) {
+		losers <- loser
+	}))
+	defer r.Close()
+
+	req1, _ := http.NewRequest("GET", fast.URL, nil)
+	req2, _ := http.NewRequest("GET", slow.URL, nil)
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	select {
+	case loser := <-losers:
+		if loser.Err != nil || loser.StatusCode != http.StatusAccepted || loser.Header.Get(VersionHeader) != "1" {
+			t.Fatalf("Unexpected loser %+v", loser)
+		}
+		if loser.Request.URL.Host != req2.URL.Host || loser.Elapsed < 30*time.Millisecond {
+			t.Fatalf("Expected the slow request, got %s after %v", loser.Request.URL, loser.Elapsed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the headers of the loser")
+	}
+}
+
+func TestLoserHeaders_FailedBeforeWinner(t *testing.T) {
+	winner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+	}))
+	defer winner.Close()
+
+	losers := make(chan LoserHeaders, 2)
+	r := New(WithLoserHeaders(time.Second, func(loser LoserHeaders) {
+		losers <- loser
+	}))
+	defer r.Close()
+
+	failing, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	req, _ := http.NewRequest("GET", winner.URL, nil)
+	res, err := r.Between(failing, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	select {
+	case loser := <-losers:
+		if loser.Err == nil || loser.Request.URL.Host != failing.URL.Host {
+			t.Fatalf("Expected the failed request, got %+v", loser)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the loser failing before the winner to be reported")
+	}
+	select {
+	case loser := <-losers:
+		t.Fatalf("Expected the loser to be reported once, got %+v", loser)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// the failures of a race without a winner aren't losers
+	other, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	if _, err := r.Between(failing, other); err == nil {
+		t.Fatal("Expected the race to fail")
+	}
+	select {
+	case loser := <-losers:
+		t.Fatalf("Expected no loser without a winner, got %+v", loser)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,36 @@
+package race
+
+import "time"
+
+// WithLoserCompletion lets the losers of a race run for up to the budget after the winner is returned,
+// instead of canceling them, so the Strategy observes their actual latencies and orders the targets
+// more accurately, without delaying the caller. The responses of the losers are closed as they arrive
+// and the ones still running when the budget elapses are canceled. The largest budget of the options
+// letting the losers run is used
+func WithLoserCompletion(budget time.Duration) Option {
+	return func(race *Race) {
+		if budget > race.loserBudget {
+			race.loserBudget = budget
+		}
+	}
+}
+
+// cancelLosersAfterBudget cancels the losers still running when the budget of WithLoserCompletion elapses,
+// the returned function stops the timer
+func (c *contest) cancelLosersAfterBudget() func() bool {
+	if c.race.loserBudget <= 0 {
+		return func() bool { return false }
+	}
+
+	timer := time.AfterFunc(c.race.loserBudget, func() {
+		c.mu.Lock()
+		won := c.won
+		c.mu.Unlock()
+		if won != nil {
+			c.cancelAttempts(lostTo(won))
+		} else {
+			c.cancelAttempts(nil)
+		}
+	})
+	return timer.Stop
+}
@@ -0,0 +1,72 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type finishedRecorder struct {
+	mu       sync.Mutex
+	finished map[string]error
+	done     chan struct{}
+}
+
+func (f *finishedRecorder) Order(targets []*http.Request) []*http.Request { return targets }
+
+func (f *finishedRecorder) Started(target *http.Request) {}
+
+func (f *finishedRecorder) Finished(target *http.Request, elapsed time.Duration, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.finished[target.URL.Host] = err
+	if len(f.finished) == 2 {
+		close(f.done)
+	}
+}
+
+func TestLoserCompletion(t *testing.T) {
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	for _, budget := range []time.Duration{0, time.Second} {
+		recorder := &finishedRecorder{finished: make(map[string]error), done: make(chan struct{})}
+		r := New(WithStrategy(recorder), WithLoserCompletion(budget))
+
+		req1, _ := http.NewRequest("GET", fast.URL, nil)
+		req2, _ := http.NewRequest("GET", slow.URL, nil)
+		start := time.Now()
+		res, err := r.Between(req1, req2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+			t.Fatalf("Expected the winner not to wait for the loser, took %v", elapsed)
+		}
+
+		<-recorder.done
+		r.Close()
+		loserErr := recorder.finished[req2.URL.Host]
+		if budget == 0 && loserErr == nil {
+			t.Fatal("Expected the loser to be canceled")
+		}
+		if budget > 0 && loserErr != nil {
+			t.Fatalf("Expected the loser to complete, got %v", loserErr)
+		}
+	}
+}
+
+func TestLoserCompletion_LargestBudget(t *testing.T) {
+	r := New(WithVersionSkew(VersionHeader, time.Second, func(map[string]string) {}), WithLoserCompletion(10*time.Millisecond))
+	defer r.Close()
+	if r.loserBudget != time.Second {
+		t.Fatalf("Expected the largest budget to be kept, got %v", r.loserBudget)
+	}
+}
@@ -0,0 +1,45 @@
+package race
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/sync/semaphore"
+)
+
+type slotKey struct{}
+
+// lentSlot is the slot of an attempt lent to the races started within it, e.g. by an Adapter
+type lentSlot struct {
+	race *Race
+	slot *semaphore.Weighted
+}
+
+// lendSlot marks the context of the request as an attempt holding a slot of the Race, so the races
+// started with it are nested: they can't outlive the deadline of the attempt since their contexts are
+// derived from it, and their attempts take the free slots of the Race or share the one of the attempt,
+// instead of waiting for slots that the attempts of the parent race hold
+func (race *Race) lendSlot(req *http.Request) *http.Request {
+	if race.concurrency == nil {
+		return req
+	}
+	lent := &lentSlot{race: race, slot: semaphore.NewWeighted(1)}
+	return req.WithContext(context.WithValue(req.Context(), slotKey{}, lent))
+}
+
+// acquireNestedSlot reserves a slot for an attempt of a race nested in an attempt of the Race,
+// it reports false if the race isn't nested
+func (race *Race) acquireNestedSlot(ctx context.Context) (func(), bool, error) {
+	lent, ok := ctx.Value(slotKey{}).(*lentSlot)
+	if !ok || lent.race != race {
+		return nil, false, nil
+	}
+
+	if race.concurrency.TryAcquire() {
+		return race.concurrency.Release, true, nil
+	}
+	if err := lent.slot.Acquire(ctx, 1); err != nil {
+		return nil, true, err
+	}
+	return func() { lent.slot.Release(1) }, true, nil
+}
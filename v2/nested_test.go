@@ -0,0 +1,39 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNestedRace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var r *Race
+	var deadline time.Time
+	resolver := AdapterFunc(func(req *http.Request) (*http.Response, error) {
+		deadline, _ = req.Context().Deadline()
+		req1, _ := http.NewRequest("GET", server.URL, nil)
+		req2, _ := http.NewRequest("GET", server.URL, nil)
+		return r.BetweenContext(req.Context(), req1, req2)
+	})
+	// the parent attempt holds the only slot
+	r = New(WithMaxConcurrency(1), WithAdapter("nested", resolver))
+	defer r.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, _ := http.NewRequest("GET", "nested://resolver", nil)
+	res, err := r.BetweenContext(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if expected, _ := ctx.Deadline(); !deadline.Equal(expected) {
+		t.Fatalf("Expected the nested race to have the deadline of its parent, got %s", deadline)
+	}
+}
@@ -0,0 +1,37 @@
+package race
+
+import (
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// WithTokenSource sends the requests to the target host with a token of the source,
+// so targets that belong to different identity realms can be raced. The token is fetched
+// lazily and refreshed when it expires, so hedges started late still have a valid one
+func WithTokenSource(host string, source oauth2.TokenSource) Option {
+	return func(race *Race) {
+		if race.tokenSources == nil {
+			race.tokenSources = make(map[string]oauth2.TokenSource)
+		}
+		race.tokenSources[host] = oauth2.ReuseTokenSource(nil, source)
+	}
+}
+
+// authorize returns a copy of the request with the token of its host
+func (race *Race) authorize(req *http.Request) (*http.Request, error) {
+	source, ok := race.tokenSources[req.URL.Host]
+	if !ok {
+		return req, nil
+	}
+
+	token, err := source.Token()
+	if err != nil {
+		return nil, fmt.Errorf("race: getting the token of %s: %w", req.URL.Host, err)
+	}
+
+	authorized := req.Clone(req.Context())
+	token.SetAuthHeader(authorized)
+	return authorized, nil
+}
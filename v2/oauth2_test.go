@@ -0,0 +1,62 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+type countingTokenSource struct {
+	calls int32
+}
+
+func (source *countingTokenSource) Token() (*oauth2.Token, error) {
+	atomic.AddInt32(&source.calls, 1)
+	return &oauth2.Token{AccessToken: "secret", Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func TestTokenSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := &countingTokenSource{}
+	r := New(WithTokenSource(u.Host, source))
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := r.Between(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected the token to be sent, got %d", res.StatusCode)
+		}
+	}
+
+	if source.calls != 1 {
+		t.Fatalf("Expected the token to be reused until it expires, got %d calls", source.calls)
+	}
+	if req.Header.Get("Authorization") != "" {
+		t.Fatal("Expected the original request not to be modified")
+	}
+}
@@ -0,0 +1,4 @@
+package race
+
+// Option configures a Race
+type Option func(*Race)
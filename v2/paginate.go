@@ -0,0 +1,134 @@
+package race
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// NextPage returns the URL of the page after the given one, nil if it's the last page.
+// The body of the page is buffered
+type NextPage func(page *http.Response, body []byte) (*url.URL, error)
+
+// Pages is a paginated resource, the first page is raced between the replicas
+// and the next pages are fetched from the replica that won, so the pagination is consistent
+type Pages struct {
+	race *Race
+	next NextPage
+	reqs []*http.Request
+
+	// target is the winning request as it was given, the next pages are fetched like it
+	target  *http.Request
+	nextURL *url.URL
+	done    bool
+}
+
+// Paginate returns the pages of the resource the requests point to,
+// the pages are fetched by the Next method of Pages
+func (race *Race) Paginate(next NextPage, reqs ...*http.Request) *Pages {
+	return &Pages{race: race, next: next, reqs: reqs}
+}
+
+// Next returns the next page with a buffered body, io.EOF after the last page
+func (p *Pages) Next() (*http.Response, error) {
+	return p.NextContext(context.Background())
+}
+
+// NextContext is like Next but the page is canceled when the context is done
+func (p *Pages) NextContext(ctx context.Context) (*http.Response, error) {
+	if p.done {
+		return nil, io.EOF
+	}
+
+	var res *http.Response
+	var err error
+	if p.target == nil {
+		var result Result
+		result, err = p.race.betweenResult(ctx, p.reqs)
+		res, p.target = result.Response, result.Request
+	} else {
+		req := p.target.Clone(withTarget(ctx, p.target))
+		req.URL = p.nextURL
+		req.Host = p.nextURL.Host
+		// every page is a request of its own, with its own key
+		req.Header.Del(DefaultIdempotencyHeader)
+		if p.race.idempotencyHeader != "" {
+			req.Header.Del(p.race.idempotencyHeader)
+		}
+
+		c := p.race.newContestContext(ctx, p.race.client.Timeout)
+		res, err = c.run([]Tier{{Requests: []*http.Request{req}}})
+		c.finish()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	p.nextURL, err = p.next(res, body)
+	if err != nil {
+		return nil, err
+	}
+	p.done = p.nextURL == nil
+
+	return res, nil
+}
+
+// ErrUnknownPage is returned by LinkNext and JSONCursor for a page without a Request,
+// e.g. the response of an Adapter, when the URL of the next page is relative to it
+var ErrUnknownPage = errors.New("race: the URL of the page is unknown")
+
+// LinkNext finds the next page in the Link header of the page, i.e. the link with rel="next"
+func LinkNext(page *http.Response, body []byte) (*url.URL, error) {
+	for _, l := range parseLinks(page.Header.Values("Link")) {
+		if !l.is("next") {
+			continue
+		}
+		if page.Request == nil {
+			next, err := url.Parse(l.target)
+			if err == nil && !next.IsAbs() {
+				return nil, ErrUnknownPage
+			}
+			return next, err
+		}
+		return page.Request.URL.Parse(l.target)
+	}
+	return nil, nil
+}
+
+// JSONCursor returns a NextPage that reads the cursor of the next page from the given field
+// of the JSON body, nested fields are separated by dots, and sets it as the given query parameter
+// of the URL of the page. The page is the last one when the cursor is missing or empty
+func JSONCursor(field, param string) NextPage {
+	return func(page *http.Response, body []byte) (*url.URL, error) {
+		v, err := decodeJSON(body)
+		if err != nil {
+			return nil, err
+		}
+
+		cursor := jsonField(v, field)
+		if cursor == nil || cursor == "" {
+			return nil, nil
+		}
+
+		if page.Request == nil {
+			return nil, ErrUnknownPage
+		}
+		next := *page.Request.URL
+		query := next.Query()
+		query.Set(param, fmt.Sprint(cursor))
+		next.RawQuery = query.Encode()
+		return &next, nil
+	}
+}
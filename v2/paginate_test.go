@@ -0,0 +1,177 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPaginateLinks(t *testing.T) {
+	var slowHits int32
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&slowHits, 1)
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowServer.Close()
+
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		if page != "3" {
+			var next int
+			fmt.Sscan(page, &next)
+			w.Header().Set("Link", fmt.Sprintf(`</?page=1>; rel="first", </?page=%d>; rel="next"`, next+1))
+		}
+		w.Write([]byte(page))
+	}))
+	defer fastServer.Close()
+
+	r := New()
+	defer r.Close()
+
+	req1, _ := http.NewRequest("GET", slowServer.URL, nil)
+	req2, _ := http.NewRequest("GET", fastServer.URL, nil)
+	pages := r.Paginate(LinkNext, req1, req2)
+
+	var got string
+	for {
+		res, err := pages.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		got += string(body)
+	}
+
+	if got != "123" {
+		t.Fatalf("Expected 3 pages, got %q", got)
+	}
+	if n := atomic.LoadInt32(&slowHits); n != 1 {
+		t.Fatalf("Expected only the first page to be raced, the slow server got %d hits", n)
+	}
+}
+
+func TestPaginateCursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Write([]byte(`{"items": [1], "meta": {"next": "abc"}}`))
+		case "abc":
+			w.Write([]byte(`{"items": [2], "meta": {"next": ""}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/items?limit=1", nil)
+	pages := New().Paginate(JSONCursor("meta.next", "cursor"), req)
+
+	count := 0
+	for {
+		res, err := pages.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Unexpected status %d for %s", res.StatusCode, res.Request.URL)
+		}
+		if res.Request.URL.Query().Get("limit") != "1" {
+			t.Fatal("Expected the query of the first page to be kept")
+		}
+		count++
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2 pages, got %d", count)
+	}
+}
+
+func TestLinkNext_UnknownPage(t *testing.T) {
+	page := &http.Response{Header: http.Header{"Link": {`</items?page=2>; rel="next"`}}}
+	if _, err := LinkNext(page, nil); err != ErrUnknownPage {
+		t.Fatalf("Expected ErrUnknownPage, got %v", err)
+	}
+
+	page.Header.Set("Link", `<http://example.com/items?page=2>; rel="next"`)
+	next, err := LinkNext(page, nil)
+	if err != nil || next.String() != "http://example.com/items?page=2" {
+		t.Fatalf("Expected the absolute link, got %v %v", next, err)
+	}
+
+	if _, err := JSONCursor("next", "cursor")(&http.Response{}, []byte(`{"next":"x"}`)); err != ErrUnknownPage {
+		t.Fatalf("Expected ErrUnknownPage, got %v", err)
+	}
+}
+
+func TestPaginate_IdempotencyKeys(t *testing.T) {
+	var mu sync.Mutex
+	keys := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys[r.Header.Get(DefaultIdempotencyHeader)] = true
+		mu.Unlock()
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", `</?page=2>; rel="next"`)
+		}
+	}))
+	defer server.Close()
+
+	r := New(WithIdempotencyKey(""))
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	pages := r.Paginate(LinkNext, req)
+	for {
+		_, err := pages.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(keys) != 2 || keys[""] {
+		t.Fatalf("Expected every page to have its own key, got %v", keys)
+	}
+}
+
+func TestPaginate_Context(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	r := New()
+	defer r.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if _, err := r.Paginate(LinkNext, req).NextContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the page to be canceled, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package race
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// prefetched is a buffered response of a preload link
+type prefetched struct {
+	res     *http.Response
+	body    []byte
+	expires time.Time
+}
+
+// WithPreload prefetches in the background the links with rel=preload of the Early Hints the
+// winner of a race sent, through the same Race, so they are ready when the caller asks for them.
+// Relative links are resolved against the URL of the winner and are requested without the headers
+// of the race. The prefetched responses are buffered and kept for the given time, the first race of
+// GET requests for a prefetched resource, by Between or BetweenContext, is answered with it, see Prefetched
+func WithPreload(ttl time.Duration) Option {
+	return func(race *Race) {
+		race.preloadTTL = ttl
+	}
+}
+
+// Prefetched returns the prefetched response of the URL, it can be taken only once
+func (race *Race) Prefetched(rawURL string) (*http.Response, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, false
+	}
+	return race.takePrefetched(u)
+}
+
+// prefetchedResponse returns the prefetched response of the resource of the GET requests of a race
+func (race *Race) prefetchedResponse(reqs []*http.Request) (*http.Response, bool) {
+	race.mu.Lock()
+	empty := len(race.prefetched) == 0
+	race.mu.Unlock()
+	if empty {
+		return nil, false
+	}
+
+	for _, req := range reqs {
+		if req.Method != http.MethodGet && req.Method != "" || req.Body != nil && req.Body != http.NoBody {
+			return nil, false
+		}
+	}
+	for _, req := range reqs {
+		if res, ok := race.takePrefetched(req.URL); ok {
+			return res, true
+		}
+	}
+	return nil, false
+}
+
+// takePrefetched takes the prefetched response of the resource of the URL
+func (race *Race) takePrefetched(u *url.URL) (*http.Response, bool) {
+	key := race.resourceURL(u).String()
+	race.mu.Lock()
+	p, ok := race.prefetched[key]
+	delete(race.prefetched, key)
+	race.mu.Unlock()
+	if !ok || time.Now().After(p.expires) {
+		return nil, false
+	}
+
+	p.res.Body = ioutil.NopCloser(bytes.NewReader(p.body))
+	return p.res, true
+}
+
+// preload prefetches the preload links of the hints of the winner, relative to its URL
+func (race *Race) preload(base *url.URL, hints []http.Header) {
+	if race.preloadTTL <= 0 {
+		return
+	}
+
+	for _, hint := range hints {
+		for _, l := range parseLinks(hint.Values("Link")) {
+			if !l.is("preload") {
+				continue
+			}
+			target, err := base.Parse(l.target)
+			if err != nil {
+				continue
+			}
+			req, err := http.NewRequest(http.MethodGet, target.String(), nil)
+			if err != nil {
+				continue
+			}
+
+			race.background.Add(1)
+			go func() {
+				defer race.background.Done()
+				race.prefetch(req)
+			}()
+		}
+	}
+}
+
+func (race *Race) prefetch(req *http.Request) {
+	res, err := race.Between(req)
+	if err != nil {
+		return
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	race.mu.Lock()
+	defer race.mu.Unlock()
+
+	if race.prefetched == nil {
+		race.prefetched = make(map[string]prefetched)
+	}
+	for url, p := range race.prefetched {
+		if now.After(p.expires) {
+			delete(race.prefetched, url)
+		}
+	}
+	race.prefetched[race.resourceURL(req.URL).String()] = prefetched{res: res, body: body, expires: now.Add(race.preloadTTL)}
+}
@@ -0,0 +1,104 @@
+package race
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPreload(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "</style.css>; rel=preload; as=style, </next>; rel=next")
+		w.WriteHeader(http.StatusEarlyHints)
+		w.Write([]byte("page"))
+	})
+	mux.HandleFunc("/style.css", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("style"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	r := New(WithPreload(time.Minute))
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	var prefetched *http.Response
+	for i := 0; i < 100 && prefetched == nil; i++ {
+		prefetched, _ = r.Prefetched(server.URL + "/style.css")
+		time.Sleep(10 * time.Millisecond)
+	}
+	if prefetched == nil {
+		t.Fatal("Expected the preload link to be prefetched")
+	}
+	body, _ := ioutil.ReadAll(prefetched.Body)
+	if string(body) != "style" {
+		t.Fatalf("Unexpected body %q", body)
+	}
+
+	if _, ok := r.Prefetched(server.URL + "/style.css"); ok {
+		t.Fatal("Expected the prefetched response to be taken once")
+	}
+	if _, ok := r.Prefetched(server.URL + "/next"); ok {
+		t.Fatal("Expected only the preload links to be prefetched")
+	}
+}
+
+func TestPreload_Between(t *testing.T) {
+	var styles int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		w.WriteHeader(http.StatusEarlyHints)
+		w.Write([]byte("page"))
+	})
+	mux.HandleFunc("/style.css", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&styles, 1)
+		w.Write([]byte("style"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	r := New(WithPreload(time.Minute))
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	for i := 0; i < 100; i++ {
+		r.mu.Lock()
+		n := len(r.prefetched)
+		r.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	req, _ = http.NewRequest("GET", server.URL+"/style.css", nil)
+	res, err = r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "style" {
+		t.Fatalf("Unexpected body %q", body)
+	}
+	if n := atomic.LoadInt32(&styles); n != 1 {
+		t.Fatalf("Expected the race to be answered with the prefetched response, got %d requests", n)
+	}
+}
@@ -0,0 +1,147 @@
+package race
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Prepared is a race prepared once and made many times, the URLs of its targets are templates
+// whose placeholders, e.g. https://{region}.example.com/users/{id}, are filled at call time
+type Prepared struct {
+	race      *Race
+	method    string
+	header    http.Header
+	templates []urlTemplate
+}
+
+// urlTemplate is a parsed URL template, the literal parts alternate with the placeholders
+type urlTemplate struct {
+	literals []string
+	params   []templateParam
+}
+
+type templateParam struct {
+	name string
+	// escape encodes the value for its part of the URL
+	escape func(string) (string, error)
+}
+
+// Prepare parses the URL templates of the targets once, the requests are made by Do and DoWithParams
+// with the method and a copy of the header. A placeholder is a name between braces, its value is escaped
+// for the part of the URL it's in, the scheme and host parts aren't escaped but the host can't hold a path,
+// user info, query or fragment
+func (race *Race) Prepare(method string, header http.Header, templates ...string) (*Prepared, error) {
+	p := &Prepared{race: race, method: method, header: header.Clone()}
+	for _, template := range templates {
+		t, err := parseTemplate(template)
+		if err != nil {
+			return nil, err
+		}
+		p.templates = append(p.templates, t)
+	}
+	return p, nil
+}
+
+// Do makes the race of the prepared targets
+func (p *Prepared) Do(ctx context.Context) (*http.Response, error) {
+	return p.DoWithParams(ctx, nil)
+}
+
+// DoWithParams fills the placeholders of the templates with the params and makes the race
+func (p *Prepared) DoWithParams(ctx context.Context, params map[string]string) (*http.Response, error) {
+	reqs := make([]*http.Request, len(p.templates))
+	for i, t := range p.templates {
+		u, err := t.expand(params)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest(p.method, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if p.header != nil {
+			req.Header = p.header.Clone()
+		}
+		reqs[i] = req
+	}
+
+	return p.race.BetweenContext(ctx, reqs...)
+}
+
+func parseTemplate(template string) (urlTemplate, error) {
+	var t urlTemplate
+	rest := template
+	var literal strings.Builder
+	for offset := 0; len(rest) > 0; {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			literal.WriteString(rest)
+			break
+		}
+		if rest[open] == '}' {
+			return t, fmt.Errorf("race: unexpected } in the template %q", template)
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return t, fmt.Errorf("race: unclosed { in the template %q", template)
+		}
+		literal.WriteString(rest[:open])
+		name := rest[open+1 : open+end]
+		if name == "" || strings.ContainsAny(name, "{/") {
+			return t, fmt.Errorf("race: invalid placeholder %q in the template %q", name, template)
+		}
+
+		t.literals = append(t.literals, literal.String())
+		t.params = append(t.params, templateParam{name: name, escape: escaper(template[:offset+open])})
+		literal.Reset()
+
+		offset += open + end + 1
+		rest = rest[open+end+1:]
+	}
+	t.literals = append(t.literals, literal.String())
+	return t, nil
+}
+
+// escaper returns how to escape a value following the prefix of a URL
+func escaper(prefix string) func(string) (string, error) {
+	if strings.ContainsAny(prefix, "?#") {
+		return noError(url.QueryEscape)
+	}
+	if scheme := strings.Index(prefix, "://"); scheme >= 0 && !strings.Contains(prefix[scheme+3:], "/") {
+		return hostValue
+	}
+	return noError(url.PathEscape)
+}
+
+func noError(escape func(string) string) func(string) (string, error) {
+	return func(s string) (string, error) { return escape(s), nil }
+}
+
+// hostValue returns the value of a placeholder in the host, it can't send the request to another host
+func hostValue(s string) (string, error) {
+	if strings.ContainsAny(s, "/\\@?#") {
+		return "", fmt.Errorf("race: invalid host %q", s)
+	}
+	return s, nil
+}
+
+func (t urlTemplate) expand(params map[string]string) (string, error) {
+	var u strings.Builder
+	for i, param := range t.params {
+		value, ok := params[param.name]
+		if !ok {
+			return "", fmt.Errorf("race: missing parameter %q", param.name)
+		}
+		escaped, err := param.escape(value)
+		if err != nil {
+			return "", err
+		}
+		u.WriteString(t.literals[i])
+		u.WriteString(escaped)
+	}
+	u.WriteString(t.literals[len(t.literals)-1])
+	return u.String(), nil
+}
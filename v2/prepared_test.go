@@ -0,0 +1,61 @@
+package race
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPreparedDoWithParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Tenant") + " " + r.URL.EscapedPath() + " " + r.URL.Query().Get("q")))
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	r := New()
+	defer r.Close()
+
+	p, err := r.Prepare("GET", http.Header{"X-Tenant": {"acme"}}, "http://{host}/users/{id}?q={query}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := p.DoWithParams(context.Background(), map[string]string{"host": host, "id": "a/b", "query": "x&y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "acme /users/a%2Fb x&y" {
+		t.Fatalf("Unexpected body %q", body)
+	}
+
+	if _, err := p.DoWithParams(context.Background(), map[string]string{"host": host}); err == nil {
+		t.Fatal("Expected an error for a missing parameter")
+	}
+}
+
+func TestPrepareInvalidTemplate(t *testing.T) {
+	for _, template := range []string{"http://{host/", "http://host}/", "http://host/{}"} {
+		if _, err := New().Prepare("GET", nil, template); err == nil {
+			t.Fatalf("Expected %q to be invalid", template)
+		}
+	}
+}
+
+func TestPreparedInvalidHost(t *testing.T) {
+	p, err := New().Prepare("GET", nil, "http://{host}/users/{id}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, host := range []string{"evil.com/", "x@evil.com", "evil.com?", "evil.com#", `evil.com\`} {
+		if _, err := p.DoWithParams(context.Background(), map[string]string{"host": host, "id": "1"}); err == nil || !strings.Contains(err.Error(), "invalid host") {
+			t.Fatalf("Expected the host %q to be rejected, got %v", host, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package race
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+type priorityKey struct{}
+
+// priority is the rank of an attempt waiting for a slot of the Race, the attempts of the earlier
+// tiers go first, then the primaries before the hedges, then the ones that waited longer
+type priority struct {
+	tier  int
+	index int
+	// hedge is false for the primaries: the first tier of a race when others follow it,
+	// otherwise the first attempt
+	hedge bool
+}
+
+// withPriority returns a context whose attempt has the priority
+func withPriority(parent context.Context, p priority) context.Context {
+	return context.WithValue(parent, priorityKey{}, p)
+}
+
+// priorityOf returns the priority of the attempt of the context, the attempts made
+// outside a race have the priority of a primary
+func priorityOf(ctx context.Context) priority {
+	p, _ := ctx.Value(priorityKey{}).(priority)
+	return p
+}
+
+// limiter limits the in-flight attempts like a semaphore, but its waiters are served by priority
+// instead of FIFO, so when the slots are scarce the hedges wait and the primaries are made
+type limiter struct {
+	mu      sync.Mutex
+	size    int64
+	used    int64
+	seq     uint64
+	waiters waiters
+}
+
+func newLimiter(size int64) *limiter {
+	return &limiter{size: size}
+}
+
+// TryAcquire takes a free slot without waiting, it reports whether there was one
+func (l *limiter) TryAcquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.used < l.size && len(l.waiters) == 0 {
+		l.used++
+		return true
+	}
+	return false
+}
+
+// Acquire takes a slot, waiting for it behind the waiters of higher priority until the context is done
+func (l *limiter) Acquire(ctx context.Context, p priority) error {
+	l.mu.Lock()
+	if l.used < l.size && len(l.waiters) == 0 {
+		l.used++
+		l.mu.Unlock()
+		return nil
+	}
+
+	w := &waiter{priority: p, seq: l.seq, ready: make(chan struct{})}
+	l.seq++
+	heap.Push(&l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		select {
+		case <-w.ready:
+			// the slot was given while the context was done
+			l.mu.Unlock()
+			l.Release()
+		default:
+			heap.Remove(&l.waiters, w.index)
+			l.mu.Unlock()
+		}
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot and gives the free slots to the waiters of the highest priority
+func (l *limiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.used--
+	for l.used < l.size && len(l.waiters) > 0 {
+		w := heap.Pop(&l.waiters).(*waiter)
+		l.used++
+		close(w.ready)
+	}
+}
+
+type waiter struct {
+	priority priority
+	seq      uint64
+	ready    chan struct{}
+	// index is the position of the waiter in the heap
+	index int
+}
+
+// waiters is a heap of waiters, the first one has the highest priority
+type waiters []*waiter
+
+func (w waiters) Len() int { return len(w) }
+
+func (w waiters) Less(i, j int) bool {
+	a, b := w[i], w[j]
+	if a.priority.tier != b.priority.tier {
+		return a.priority.tier < b.priority.tier
+	}
+	if a.priority.hedge != b.priority.hedge {
+		return !a.priority.hedge
+	}
+	return a.seq < b.seq
+}
+
+func (w waiters) Swap(i, j int) {
+	w[i], w[j] = w[j], w[i]
+	w[i].index = i
+	w[j].index = j
+}
+
+func (w *waiters) Push(x any) {
+	waiter := x.(*waiter)
+	waiter.index = len(*w)
+	*w = append(*w, waiter)
+}
+
+func (w *waiters) Pop() any {
+	old := *w
+	waiter := old[len(old)-1]
+	old[len(old)-1] = nil
+	*w = old[:len(old)-1]
+	return waiter
+}
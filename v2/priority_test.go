@@ -0,0 +1,70 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterPriority(t *testing.T) {
+	l := newLimiter(1)
+	if !l.TryAcquire() {
+		t.Fatal("Expected a free slot")
+	}
+
+	order := make(chan string, 3)
+	wait := func(name string, p priority) {
+		l.mu.Lock()
+		n := len(l.waiters)
+		l.mu.Unlock()
+		go func() {
+			if err := l.Acquire(context.Background(), p); err != nil {
+				t.Error(err)
+			}
+			order <- name
+		}()
+		for {
+			l.mu.Lock()
+			queued := len(l.waiters) > n
+			l.mu.Unlock()
+			if queued {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wait("later tier", priority{tier: 1, index: 2, hedge: true})
+	wait("hedge", priority{tier: 0, index: 1, hedge: true})
+	wait("primary", priority{tier: 0, index: 0})
+
+	for _, expected := range []string{"primary", "hedge", "later tier"} {
+		l.Release()
+		if got := <-order; got != expected {
+			t.Fatalf("Expected the %s to get the slot, got the %s", expected, got)
+		}
+	}
+	l.Release()
+	if !l.TryAcquire() {
+		t.Fatal("Expected the slot to be free")
+	}
+}
+
+func TestLimiterCanceledWaiter(t *testing.T) {
+	l := newLimiter(1)
+	l.TryAcquire()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(ctx, priority{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the wait to time out, got %v", err)
+	}
+	if len(l.waiters) != 0 {
+		t.Fatalf("Expected the waiter to be removed, got %d", len(l.waiters))
+	}
+
+	l.Release()
+	if !l.TryAcquire() {
+		t.Fatal("Expected the slot to be free")
+	}
+}
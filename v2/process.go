@@ -0,0 +1,29 @@
+package race
+
+import "net/http"
+
+// Processor post-processes the winner of a race before it's returned, e.g. to verify,
+// measure or annotate it, index is the position of the winner in the race.
+// When it returns an error the body of the response is closed and the race fails with the error
+type Processor func(res *http.Response, index int) (*http.Response, error)
+
+// WithProcessor appends the processor to the chain applied to the winners,
+// the processors are applied in the order of the options
+func WithProcessor(processor Processor) Option {
+	return func(race *Race) {
+		race.processors = append(race.processors, processor)
+	}
+}
+
+// process applies the chain of processors to the winner
+func (race *Race) process(res *http.Response, index int) (*http.Response, error) {
+	for _, processor := range race.processors {
+		processed, err := processor(res, index)
+		if err != nil {
+			res.Body.Close()
+			return nil, err
+		}
+		res = processed
+	}
+	return res, nil
+}
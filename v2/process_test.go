@@ -0,0 +1,51 @@
+package race
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProcessors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Checksum", r.URL.Query().Get("checksum"))
+	}))
+	defer server.Close()
+
+	var order []string
+	errInvalid := errors.New("invalid checksum")
+	r := New(
+		WithAnnotation(""),
+		WithProcessor(func(res *http.Response, index int) (*http.Response, error) {
+			order = append(order, "verify")
+			if res.Header.Get("X-Checksum") != "ok" {
+				return nil, errInvalid
+			}
+			return res, nil
+		}),
+		WithProcessor(func(res *http.Response, index int) (*http.Response, error) {
+			order = append(order, "measure")
+			return res, nil
+		}),
+	)
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"?checksum=ok", nil)
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.Header.Get(DefaultAnnotationHeader) != RolePrimary {
+		t.Fatal("Expected the winner to be annotated")
+	}
+	if len(order) != 2 || order[0] != "verify" || order[1] != "measure" {
+		t.Fatalf("Expected the processors in the order of the options, got %v", order)
+	}
+
+	req, _ = http.NewRequest("GET", server.URL+"?checksum=bad", nil)
+	if _, err := r.Between(req); err != errInvalid {
+		t.Fatalf("Expected the error of the processor, got %v", err)
+	}
+}
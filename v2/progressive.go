@@ -0,0 +1,66 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Progressive makes the requests simultaneously, ordered from the most to the least authoritative, e.g. the
+// origin then replicas or caches, and calls fn with the first response as soon as it arrives, then with every
+// later response of a more authoritative request within the window, so a UI can render fast data and patch it
+// with authoritative data. index is the position of the request and fn must close the responses. Progressive
+// returns once the window elapsed after the first response or the first request answered, it fails like a race
+// if no request succeeded. The other responses are closed and the remaining requests canceled
+func (race *Race) Progressive(ctx context.Context, window time.Duration, fn func(res *http.Response, index int), reqs ...*http.Request) error {
+	if len(reqs) == 0 {
+		return ErrNoRequests
+	}
+	if err := race.checkIdempotent(reqs); err != nil {
+		return err
+	}
+
+	c := race.newContestContext(ctx, race.client.Timeout)
+	defer c.finish()
+
+	c.startAll(reqs)
+
+	var deadline <-chan time.Time
+	best := len(reqs)
+	errs := make([]error, len(reqs))
+	for answered := 0; answered < len(reqs) && best > 0; answered++ {
+		var o outcome
+		select {
+		case o = <-c.results:
+		case <-deadline:
+			return nil
+		}
+
+		if o.err != nil {
+			errs[o.index] = o.err
+			continue
+		}
+		if o.index > best {
+			o.res.Body.Close()
+			continue
+		}
+
+		if deadline == nil {
+			timer := time.NewTimer(window)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		best = o.index
+		fn(c.keep(o.index, o.res), o.index)
+	}
+
+	if best == len(reqs) {
+		return failedAttempts(reqs, errs)
+	}
+	return nil
+}
+
+// Progressive calls fn with the first response and then with the responses of more authoritative requests
+func Progressive(ctx context.Context, window time.Duration, fn func(res *http.Response, index int), reqs ...*http.Request) error {
+	return New().Progressive(ctx, window, fn, reqs...)
+}
@@ -0,0 +1,64 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProgressive(t *testing.T) {
+	server := func(delay time.Duration) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+			}
+		}))
+	}
+	origin := server(150 * time.Millisecond)
+	defer origin.Close()
+	replica := server(20 * time.Millisecond)
+	defer replica.Close()
+	cache := server(0)
+	defer cache.Close()
+	stale := server(50 * time.Millisecond)
+	defer stale.Close()
+
+	requests := func() []*http.Request {
+		reqs := make([]*http.Request, 4)
+		for i, server := range []*httptest.Server{origin, replica, stale, cache} {
+			reqs[i], _ = http.NewRequest("GET", server.URL, nil)
+		}
+		return reqs
+	}
+
+	var indexes []int
+	collect := func(res *http.Response, index int) {
+		res.Body.Close()
+		indexes = append(indexes, index)
+	}
+
+	if err := Progressive(context.Background(), time.Second, collect, requests()...); err != nil {
+		t.Fatal(err)
+	}
+	// the stale replica answers after a more authoritative one
+	if !reflect.DeepEqual(indexes, []int{3, 1, 0}) {
+		t.Fatalf("Expected the cache, the replica and the origin, got %v", indexes)
+	}
+
+	indexes = nil
+	if err := Progressive(context.Background(), 80*time.Millisecond, collect, requests()...); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(indexes, []int{3, 1}) {
+		t.Fatalf("Expected the origin to miss the window, got %v", indexes)
+	}
+
+	failing, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	if err := Progressive(context.Background(), time.Second, collect, failing); err == nil {
+		t.Fatal("Expected the race to fail")
+	}
+}
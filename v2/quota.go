@@ -0,0 +1,160 @@
+package race
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded is returned for an attempt that was skipped because
+// its target has used up its quota for the current period
+var ErrQuotaExceeded = errors.New("race: target has exceeded its quota")
+
+// QuotaPeriod is the period after which the usage of a target is reset
+type QuotaPeriod int
+
+const (
+	// Daily resets the usage at midnight UTC
+	Daily QuotaPeriod = iota
+	// Monthly resets the usage at the start of every month, UTC
+	Monthly
+)
+
+// Quota is a hard cap on the usage of a target, zero means no limit
+type Quota struct {
+	Period   QuotaPeriod
+	Requests int64
+	Bytes    int64
+}
+
+// Usage is the consumption of a target in the current period
+type Usage struct {
+	Requests int64
+	Bytes    int64
+}
+
+// UsageSink receives the consumption of every target as it happens,
+// e.g. to export it to a billing system. It must be safe for concurrent use
+type UsageSink interface {
+	Record(host string, requests, bytes int64)
+}
+
+// WithQuota excludes the target host from the races once it has used up
+// its quota for the current period, the attempts are skipped with ErrQuotaExceeded.
+// Needed when some targets are metered third-party APIs
+func WithQuota(host string, quota Quota) Option {
+	return func(race *Race) {
+		race.initUsage()
+		race.quotas[host] = quota
+	}
+}
+
+// WithUsageSink sends the number of requests and the bytes of response bodies
+// of every target to the sink
+func WithUsageSink(sink UsageSink) Option {
+	return func(race *Race) {
+		race.initUsage()
+		race.usageSink = sink
+	}
+}
+
+// Usage returns the consumption of the target host in the current period,
+// it's only tracked if WithQuota or WithUsageSink is used
+func (race *Race) Usage(host string) Usage {
+	race.mu.Lock()
+	defer race.mu.Unlock()
+
+	u, ok := race.usage[host]
+	if !ok || u.period != race.period(host) {
+		return Usage{}
+	}
+	return u.Usage
+}
+
+type periodUsage struct {
+	Usage
+	period string
+}
+
+func (race *Race) initUsage() {
+	if race.usage == nil {
+		race.usage = make(map[string]*periodUsage)
+		race.quotas = make(map[string]Quota)
+	}
+}
+
+// period returns the key of the current period of the host, must be called with mu held
+func (race *Race) period(host string) string {
+	if race.quotas[host].Period == Monthly {
+		return time.Now().UTC().Format("2006-01")
+	}
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+// consume records a new request to the host unless its quota is used up
+func (race *Race) consume(host string) error {
+	if race.usage == nil {
+		return nil
+	}
+
+	race.mu.Lock()
+	period := race.period(host)
+	u, ok := race.usage[host]
+	if !ok || u.period != period {
+		u = &periodUsage{period: period}
+		race.usage[host] = u
+	}
+
+	quota := race.quotas[host]
+	if (quota.Requests > 0 && u.Requests >= quota.Requests) || (quota.Bytes > 0 && u.Bytes >= quota.Bytes) {
+		race.mu.Unlock()
+		return ErrQuotaExceeded
+	}
+	u.Requests++
+	race.mu.Unlock()
+
+	if race.usageSink != nil {
+		race.usageSink.Record(host, 1, 0)
+	}
+	return nil
+}
+
+func (race *Race) addBytes(host string, n int64) {
+	race.mu.Lock()
+	if u, ok := race.usage[host]; ok && u.period == race.period(host) {
+		u.Bytes += n
+	}
+	race.mu.Unlock()
+
+	if race.usageSink != nil {
+		race.usageSink.Record(host, 0, n)
+	}
+}
+
+// countBytes makes the body report the number of bytes read from it when it's closed
+func (race *Race) countBytes(host string, body io.ReadCloser) io.ReadCloser {
+	if race.usage == nil {
+		return body
+	}
+	return &countingBody{ReadCloser: body, onClose: func(n int64) { race.addBytes(host, n) }}
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n       int64
+	once    sync.Once
+	onClose func(n int64)
+}
+
+func (body *countingBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	body.n += int64(n)
+	return n, err
+}
+
+func (body *countingBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(func() { body.onClose(body.n) })
+	return err
+}
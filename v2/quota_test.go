@@ -0,0 +1,66 @@
+package race
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+type memorySink struct {
+	mu       sync.Mutex
+	requests map[string]int64
+	bytes    map[string]int64
+}
+
+func (sink *memorySink) Record(host string, requests, bytes int64) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	sink.requests[host] += requests
+	sink.bytes[host] += bytes
+}
+
+func TestQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sink := &memorySink{requests: make(map[string]int64), bytes: make(map[string]int64)}
+	r := New(WithQuota(u.Host, Quota{Period: Daily, Requests: 2}), WithUsageSink(sink))
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := r.Between(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.ReadAll(res.Body)
+		res.Body.Close()
+	}
+
+	_, err = r.Between(req)
+	if Classify(err) != FailureQuotaExceeded {
+		t.Fatalf("Expected the target to be excluded, got %v", err)
+	}
+
+	usage := r.Usage(u.Host)
+	if usage.Requests != 2 || usage.Bytes != 10 {
+		t.Fatalf("Expected 2 requests and 10 bytes, got %+v", usage)
+	}
+	if sink.requests[u.Host] != 2 || sink.bytes[u.Host] != 10 {
+		t.Fatalf("Expected the sink to receive 2 requests and 10 bytes, got %d and %d", sink.requests[u.Host], sink.bytes[u.Host])
+	}
+}
@@ -0,0 +1,329 @@
+// Package race provides functionality to call http requests simultaneously
+// and get the result from the fastest one.
+//
+// This is v2, github.com/mostafa-asg/race keeps the v1 API as wrappers over it
+package race
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
+)
+
+// Race between requests
+type Race struct {
+	client *http.Client
+
+	maxStreams     int
+	streamPolicy   StreamPolicy
+	queueLength    int
+	queueWait      time.Duration
+	concurrency    *limiter
+	primaryTimeout time.Duration
+	hedgeTimeout   time.Duration
+	strategy       Strategy
+	limiters       map[string]*rate.Limiter
+	quotas         map[string]Quota
+	usageSink      UsageSink
+	connRetry      bool
+	skewHeader     string
+	skewHook       func(versions map[string]string)
+	tokenSources   map[string]oauth2.TokenSource
+	dialOptions    map[string]DialOptions
+	compare        *comparison
+	comparator     Comparator
+	recorder       *flightRecorder
+	recordSink     RecordSink
+	coalesceWindow time.Duration
+	decoders       map[string]Decoder
+	earlyHints     func(winner *http.Response, hints []http.Header)
+	preloadTTL     time.Duration
+	verification   *verification
+	tieWindow      time.Duration
+	idHeader       string
+	processors     []Processor
+	brownout       *brownout
+	experiment     *experiment
+	adapters       map[string]Adapter
+	rangeCheck     *rangeCheck
+	validators     []Validator
+	checksum       *Checksum
+	trailerCheck   *trailerCheck
+
+	allowNonIdempotent bool
+	idempotencyHeader  string
+	tenantLimits       *TenantLimits
+	standby            *standby
+	loserBudget        time.Duration
+	loserHeaders       func(LoserHeaders)
+	hedgeStats         *hedgeStats
+	hedgeBudget        *hedgeBudget
+	shadowCallback     func(ShadowResult)
+	diff               *diff
+
+	maxDecompressionRatio float64
+	// authorities are the authorities of the mirrors by host
+	authorities map[string]string
+
+	// ctx is canceled by Close
+	ctx        context.Context
+	stop       context.CancelCauseFunc
+	background sync.WaitGroup
+	// ranks are recorded for WithRankOrdering and WithStandby, nil otherwise
+	ranks *latencies
+
+	mu        sync.Mutex
+	streams   map[string]*streams
+	redirects map[string]*url.URL
+	usage     map[string]*periodUsage
+	flights   map[string]*flight
+	// hostLatencies are the latencies of the successful attempts by host
+	hostLatencies map[string]*cohort
+	warmed        map[string]*warmed
+	// warmers count the running calls of Warm by race
+	warmers map[string]int
+	// prefetched is keyed by resource URL
+	prefetched map[string]prefetched
+	// tlsClients are the clients of the targets with their own TLS configuration
+	tlsClients map[*tls.Config]*http.Client
+	tenants    map[string]*tenant
+	// freshTransports are the transports without keep-alives by the transport they clone
+	freshTransports map[*http.Transport]*http.Transport
+}
+
+// Between gets a bunch of requests and makes http request simultaneously to all of them
+// the first answer will be returned
+func (race *Race) Between(reqs ...*http.Request) (*http.Response, error) {
+	return race.BetweenContext(context.Background(), reqs...)
+}
+
+// BetweenContext is like Between but the race is canceled when the context is done,
+// e.g. when the incoming request of a server handler is canceled. The attempts are made
+// with a child of the context instead of the contexts of the requests
+func (race *Race) BetweenContext(ctx context.Context, reqs ...*http.Request) (*http.Response, error) {
+	if res, ok := race.warmResponse(reqs); ok {
+		return res, nil
+	}
+	if res, ok := race.prefetchedResponse(reqs); ok {
+		return res, nil
+	}
+	if key, ok := race.coalesceKey(reqs); ok {
+		return race.coalesce(ctx, key, func(ctx context.Context) (*http.Response, error) {
+			return race.between(ctx, reqs)
+		})
+	}
+	return race.between(ctx, reqs)
+}
+
+func (race *Race) between(ctx context.Context, reqs []*http.Request) (*http.Response, error) {
+	result, err := race.betweenResult(ctx, reqs)
+	return result.Response, err
+}
+
+func (race *Race) betweenResult(ctx context.Context, reqs []*http.Request) (Result, error) {
+	reqs = race.order(reqs)
+	if len(reqs) == 0 {
+		return Result{Err: ErrNoRequests}, ErrNoRequests
+	}
+
+	c := race.newContestContext(ctx, race.client.Timeout)
+	defer c.finish()
+
+	// run all the requests concurrently
+	res, err := c.run([]Tier{{Requests: reqs}})
+	return c.result(reqs, res, err)
+}
+
+// FirstThenStart starts the given requests and if the given timeout elapses or
+// error happens it starts the other requests concurently,
+// if the Race has a Strategy it decides which of the requests starts first.
+// Without other requests the first one is limited by the timeout
+func (race *Race) FirstThenStart(first *http.Request, timeout time.Duration, reqs ...*http.Request) (*http.Response, error) {
+	return race.FirstThenStartContext(context.Background(), first, timeout, reqs...)
+}
+
+// FirstThenStartContext is like FirstThenStart but the race is canceled when the context is done
+func (race *Race) FirstThenStartContext(ctx context.Context, first *http.Request, timeout time.Duration, reqs ...*http.Request) (*http.Response, error) {
+	return race.primariesThenStart(ctx, []*http.Request{first}, timeout, reqs)
+}
+
+// PrimariesThenStart races the primaries and if the given timeout elapses or all of them fail
+// it starts the fallbacks concurrently, if the Race has a Strategy it decides which of the requests
+// are the primaries, keeping their number. Without fallbacks the primaries are limited by the timeout
+func (race *Race) PrimariesThenStart(primaries []*http.Request, timeout time.Duration, fallbacks ...*http.Request) (*http.Response, error) {
+	return race.primariesThenStart(context.Background(), primaries, timeout, fallbacks)
+}
+
+func (race *Race) primariesThenStart(ctx context.Context, primaries []*http.Request, timeout time.Duration, fallbacks []*http.Request) (*http.Response, error) {
+	result, err := race.primariesThenStartResult(ctx, primaries, timeout, fallbacks)
+	return result.Response, err
+}
+
+func (race *Race) primariesThenStartResult(ctx context.Context, primaries []*http.Request, timeout time.Duration, fallbacks []*http.Request) (Result, error) {
+	if race.strategy != nil {
+		n := len(primaries)
+		ordered := race.strategy.Order(append(append([]*http.Request(nil), primaries...), fallbacks...))
+		if n > len(ordered) {
+			n = len(ordered)
+		}
+		primaries, fallbacks = ordered[:n], ordered[n:]
+	}
+	race.noteSecondary(fallbacks)
+	if len(primaries) == 0 && len(fallbacks) == 0 {
+		return Result{Err: ErrNoRequests}, ErrNoRequests
+	}
+
+	if len(fallbacks) == 0 {
+		c := race.newContestContext(ctx, timeout)
+		c.primaries = len(primaries)
+		defer c.finish()
+		res, err := c.run([]Tier{{Requests: primaries}})
+		return c.result(primaries, res, err)
+	}
+
+	c := race.newContestContext(ctx, 0)
+	defer c.finish()
+	res, err := c.run([]Tier{
+		{Requests: primaries, Timeout: timeout},
+		{Requests: fallbacks},
+	})
+	return c.result(append(primaries[:len(primaries):len(primaries)], fallbacks...), res, err)
+}
+
+// New returns new race object with default http client
+func New(opts ...Option) *Race {
+	return NewWithClient(http.DefaultClient, opts...)
+}
+
+// NewWithClient returns new race object with the given http client
+func NewWithClient(client *http.Client, opts ...Option) *Race {
+	race := &Race{
+		client: client,
+	}
+	race.ctx, race.stop = context.WithCancelCause(context.Background())
+	for _, opt := range opts {
+		opt(race)
+	}
+
+	return race
+}
+
+// Close cancels the ongoing races and stops the work the Race does in the background,
+// e.g. comparing the losers with the winner, and waits for it. The Race must not be used after Close
+func (race *Race) Close() error {
+	race.stop(ErrClosed)
+	race.background.Wait()
+	return nil
+}
+
+// Between gets a bunch of requests and makes http request simultaneously to all of them
+// the first answer will be returned
+// if all requests failed, it will return *multierror.Error containing all errors that happened,
+// use Classify to find out why they failed
+func Between(reqs ...*http.Request) (*http.Response, error) {
+	return New().Between(reqs...)
+}
+
+// BetweenContext is like Between but the race is canceled when the context is done
+func BetweenContext(ctx context.Context, reqs ...*http.Request) (*http.Response, error) {
+	return New().BetweenContext(ctx, reqs...)
+}
+
+// BetweenWithClient is like Between but gets user's http client
+func BetweenWithClient(client *http.Client, reqs ...*http.Request) (*http.Response, error) {
+	return NewWithClient(client).Between(reqs...)
+}
+
+// FirstThenStart starts the given requests and if the given timeout elapses or
+// error happens it starts the other requests concurently
+func FirstThenStart(first *http.Request, timeout time.Duration, reqs ...*http.Request) (*http.Response, error) {
+	return New().FirstThenStart(first, timeout, reqs...)
+}
+
+// FirstThenStartContext is like FirstThenStart but the race is canceled when the context is done
+func FirstThenStartContext(ctx context.Context, first *http.Request, timeout time.Duration, reqs ...*http.Request) (*http.Response, error) {
+	return New().FirstThenStartContext(ctx, first, timeout, reqs...)
+}
+
+// do makes a single attempt of a race to the given target
+func (race *Race) do(target *http.Request) (*http.Response, error) {
+	key := target.URL.String()
+	req := race.cachedRedirect(key, target)
+
+	if !race.allow(req.URL.Host) {
+		return nil, ErrRateLimited
+	}
+	req, err := race.authorize(req)
+	if err != nil {
+		return nil, err
+	}
+	req = race.acceptGzip(req)
+
+	releaseSlot, err := race.acquireSlot(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	releaseStream, err := race.acquireStream(req.Context(), req.URL.Host)
+	if err != nil {
+		releaseSlot()
+		return nil, err
+	}
+	release := func() {
+		releaseStream()
+		releaseSlot()
+	}
+	if err := race.consume(req.URL.Host); err != nil {
+		release()
+		return nil, err
+	}
+
+	observer, _ := race.strategy.(AttemptObserver)
+	if observer != nil {
+		observer.Started(target)
+	}
+	if releaser, ok := race.strategy.(ReleaseObserver); ok {
+		releaseSlots := release
+		release = func() {
+			releaseSlots()
+			releaser.Released(target)
+		}
+	}
+	start := time.Now()
+
+	res, err := race.send(race.lendSlot(req))
+	if err == nil {
+		err = race.validate(req, res)
+	} else {
+		err = withCause(req.Context(), err)
+	}
+	if race.brownout != nil {
+		race.brownout.observe(res, err)
+	}
+	elapsed := time.Since(start)
+	if observer != nil {
+		observer.Finished(target, elapsed, err)
+	}
+	if err == nil {
+		race.recordLatency(req.URL.Host, elapsed)
+	}
+	race.ranks.observe(req.URL.Host, elapsed, err)
+	if err != nil {
+		release()
+		// losers are canceled, it doesn't mean the location is wrong
+		if req.Context().Err() == nil {
+			race.forgetRedirect(key)
+		}
+		return nil, err
+	}
+	race.rememberRedirect(key, res)
+	res.Body = &releaseOnClose{ReadCloser: race.countBytes(req.URL.Host, res.Body), release: release}
+	race.decodeAndVerify(res)
+
+	return res, nil
+}
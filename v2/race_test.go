@@ -0,0 +1,356 @@
+package race_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mostafa-asg/race/v2"
+	"github.com/mostafa-asg/race/v2/racetest"
+)
+
+const unresolvableDomain = "http://CrazyAndStrangeAndUnresolvableDomain"
+
+func TestBetweenSlowAndFast(t *testing.T) {
+	slow := []byte("slow")
+	fast := []byte("fast")
+
+	servers := racetest.NewServers(
+		racetest.Profile{Latency: 1 * time.Second, Body: slow},
+		racetest.Profile{Latency: 500 * time.Microsecond, Body: fast},
+	)
+	defer servers.Close()
+
+	res, err := race.Between(servers.Requests("/")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	resBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes.Compare(resBytes, fast)
+}
+
+func TestBetweenUnresolvableAndResolvableHost(t *testing.T) {
+	hello := []byte("hello")
+
+	servers := racetest.NewServers(racetest.Profile{Latency: 1 * time.Second, Body: hello})
+	defer servers.Close()
+
+	req1 := servers.Requests("/")[0]
+
+	req2, err := http.NewRequest("GET", unresolvableDomain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := race.Between(req2, req1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	resBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes.Compare(resBytes, hello)
+}
+
+func TestBetweenFailAndTimeoutReq(t *testing.T) {
+	hello := []byte("hello")
+
+	servers := racetest.NewServers(racetest.Profile{Latency: 2 * time.Second, Body: hello})
+	defer servers.Close()
+
+	req1 := servers.Requests("/")[0]
+
+	req2, err := http.NewRequest("GET", unresolvableDomain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := race.NewWithClient(&http.Client{
+		Timeout: 1 * time.Second,
+	})
+	res, err := r.Between(req1, req2)
+	if res != nil {
+		t.Fatal(err)
+	}
+
+	multiError, ok := err.(*multierror.Error)
+	if !ok {
+		t.Fatal("Expected error of type *multierror.Error")
+	}
+
+	if len(multiError.Errors) != 2 {
+		t.Fatal("Expected 2 errors")
+	}
+}
+
+func TestBetweenAllFailedReq(t *testing.T) {
+	req1, err := http.NewRequest("GET", unresolvableDomain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req2, err := http.NewRequest("GET", unresolvableDomain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := race.Between(req1, req2)
+	if res != nil {
+		t.Fatal(err)
+	}
+
+	multiError, ok := err.(*multierror.Error)
+	if !ok {
+		t.Fatal("Expected error of type *multierror.Error")
+	}
+
+	if len(multiError.Errors) != 2 {
+		t.Fatal("Expected 2 errors")
+	}
+}
+
+func TestFirstThenStart_ResponseFromSecond(t *testing.T) {
+	slow := []byte("slow")
+	fast := []byte("fast")
+
+	servers := racetest.NewServers(
+		racetest.Profile{Latency: 2 * time.Second, Body: slow},
+		racetest.Profile{Latency: 500 * time.Microsecond, Body: fast},
+	)
+	defer servers.Close()
+
+	reqs := servers.Requests("/")
+	res, err := race.FirstThenStart(reqs[0], 500*time.Microsecond, reqs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	resBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes.Compare(resBytes, fast)
+}
+
+func TestFirstThenStart_ResponseFromFirst(t *testing.T) {
+	slow := []byte("slow")
+	fast := []byte("fast")
+
+	servers := racetest.NewServers(
+		racetest.Profile{Latency: 2 * time.Second, Body: slow},
+		racetest.Profile{Latency: 4 * time.Second, Body: fast},
+	)
+	defer servers.Close()
+
+	reqs := servers.Requests("/")
+	res, err := race.FirstThenStart(reqs[0], 500*time.Microsecond, reqs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	resBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes.Compare(resBytes, fast)
+}
+
+func TestFirstThenStart_FirstError(t *testing.T) {
+	hello := []byte("hello")
+
+	servers := racetest.NewServers(racetest.Profile{Latency: 500 * time.Millisecond, Body: hello})
+	defer servers.Close()
+
+	req1, err := http.NewRequest("GET", unresolvableDomain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req2 := servers.Requests("/")[0]
+
+	// yes, after 60 seconds! but we won't wait that long
+	// because after error occurs, immediately req2 will be started
+	res, err := race.FirstThenStart(req1, 60*time.Second, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	resBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes.Compare(resBytes, hello)
+}
+
+func TestFirstThenStart_AllError(t *testing.T) {
+	req1, err := http.NewRequest("GET", unresolvableDomain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req2, err := http.NewRequest("GET", unresolvableDomain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// yes, after 60 seconds! but we won't wait that long
+	// because after error occurs, immediately req2 will be started
+	res, err := race.FirstThenStart(req1, 60*time.Second, req2)
+	if err == nil {
+		t.Fatal("Expected to return errors")
+	}
+	if res != nil {
+		t.Fatal("There should be no response")
+	}
+
+	multiError, ok := err.(*multierror.Error)
+	if !ok {
+		t.Fatal("Expected error of type *multierror.Error")
+	}
+
+	if len(multiError.Errors) != 2 {
+		t.Fatal("Expected 2 errors")
+	}
+}
+
+func TestFirstThenStart_NoFallbacks(t *testing.T) {
+	servers := racetest.NewServers(racetest.Profile{Latency: time.Second})
+	defer servers.Close()
+
+	req := servers.Requests("/")[0]
+
+	start := time.Now()
+	if _, err := race.FirstThenStart(req, 50*time.Millisecond); race.Classify(err) != race.FailureTimeout {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("Expected the request to be limited by the timeout")
+	}
+}
+
+func TestPrimariesThenStart(t *testing.T) {
+	servers := racetest.NewServers(racetest.Profile{Body: []byte("primary")}, racetest.Profile{})
+	defer servers.Close()
+
+	reqs := servers.Requests("/")
+	req1, _ := http.NewRequest("GET", unresolvableDomain, nil)
+
+	res, err := race.New().PrimariesThenStart([]*http.Request{req1, reqs[0]}, time.Second, reqs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, _ := ioutil.ReadAll(res.Body)
+	if string(body) != "primary" {
+		t.Fatalf("Expected the primaries to race, got %q", body)
+	}
+	if servers.Hits(1) != 0 {
+		t.Fatal("Expected the fallback not to start while a primary is running")
+	}
+}
+
+func TestBetweenContext_Canceled(t *testing.T) {
+	servers := racetest.NewServers(racetest.Profile{Latency: 5 * time.Second}, racetest.Profile{Latency: 5 * time.Second})
+	defer servers.Close()
+
+	reqs := servers.Requests("/")
+	req1, req2 := reqs[0], reqs[1]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, err := race.BetweenContext(ctx, req1, req2); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the race to be canceled, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("Expected the race to stop with the context")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := race.FirstThenStartContext(ctx, req1, time.Second, req2); race.Classify(err) != race.FailureTimeout {
+		t.Fatalf("Expected the race to time out, got %v", err)
+	}
+}
+
+func TestLosersCanceled(t *testing.T) {
+	canceled := make(chan struct{}, 2)
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second):
+		case <-r.Context().Done():
+			canceled <- struct{}{}
+		}
+	}))
+	defer slowServer.Close()
+
+	fastServer := racetest.NewServers(racetest.Profile{Body: []byte("fast")})
+	defer fastServer.Close()
+
+	// without a timeout nothing but the winner stops the losers
+	transport := &http.Transport{}
+	r := race.NewWithClient(&http.Client{Transport: transport})
+	before := runtime.NumGoroutine()
+
+	slow, _ := http.NewRequest("GET", slowServer.URL, nil)
+	fast := fastServer.Requests("/")[0]
+	res, err := r.Between(slow, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the loser to be canceled once the winner arrived")
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil || string(body) != "fast" {
+		t.Fatalf("Expected the body of the winner, got %q, %v", body, err)
+	}
+	res.Body.Close()
+
+	res, err = r.FirstThenStart(slow, 10*time.Millisecond, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the first request to be canceled once the fallback won")
+	}
+	res.Body.Close()
+
+	transport.CloseIdleConnections()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("Expected the attempts to stop, %d goroutines are left of %d", n, before)
+	}
+}
@@ -0,0 +1,142 @@
+// Package racetest provides mock targets for testing races
+package racetest
+
+import (
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/mostafa-asg/race/v2"
+)
+
+// Profile is how a mock target answers
+type Profile struct {
+	// Latency is the time before the response headers are sent
+	Latency time.Duration
+	// Jitter is the maximum of a random duration added to the latency
+	Jitter time.Duration
+	// ErrorRate is the fraction of the requests (between 0 and 1) answered with ErrorStatus
+	ErrorRate float64
+	// ErrorStatus is the status code of the errors, http.StatusInternalServerError if it's zero
+	ErrorStatus int
+	// Body is the body of the successful responses
+	Body []byte
+	// Bandwidth limits the body to the given bytes per second, zero means no limit
+	Bandwidth int
+}
+
+// Servers are mock targets, one httptest server per profile
+type Servers struct {
+	servers []*httptest.Server
+	hits    []int32
+}
+
+// NewServers starts a server for every profile, they must be closed by Close
+func NewServers(profiles ...Profile) *Servers {
+	s := &Servers{hits: make([]int32, len(profiles))}
+	for i, profile := range profiles {
+		s.servers = append(s.servers, httptest.NewServer(s.handler(i, profile)))
+	}
+	return s
+}
+
+func (s *Servers) handler(i int, profile Profile) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.hits[i], 1)
+
+		latency := profile.Latency
+		if profile.Jitter > 0 {
+			latency += time.Duration(rand.Int63n(int64(profile.Jitter)))
+		}
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+
+		if profile.ErrorRate > 0 && rand.Float64() < profile.ErrorRate {
+			status := profile.ErrorStatus
+			if status == 0 {
+				status = http.StatusInternalServerError
+			}
+			w.WriteHeader(status)
+			return
+		}
+
+		if profile.Bandwidth <= 0 {
+			w.Write(profile.Body)
+			return
+		}
+
+		// write the body in chunks of a tenth of a second
+		chunk := profile.Bandwidth / 10
+		if chunk == 0 {
+			chunk = 1
+		}
+		flusher, _ := w.(http.Flusher)
+		for body := profile.Body; len(body) > 0; {
+			n := chunk
+			if n > len(body) {
+				n = len(body)
+			}
+			if _, err := w.Write(body[:n]); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			body = body[n:]
+
+			select {
+			case <-time.After(time.Second * time.Duration(n) / time.Duration(profile.Bandwidth)):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+}
+
+// URLs returns the URLs of the servers in the order of the profiles
+func (s *Servers) URLs() []string {
+	urls := make([]string, len(s.servers))
+	for i, server := range s.servers {
+		urls[i] = server.URL
+	}
+	return urls
+}
+
+// Targets returns the servers as targets in the order of the profiles
+func (s *Servers) Targets() []race.Target {
+	targets := make([]race.Target, len(s.servers))
+	for i, server := range s.servers {
+		targets[i] = race.Target{URL: server.URL}
+	}
+	return targets
+}
+
+// Requests returns a GET request for the path to every server in the order of the profiles
+func (s *Servers) Requests(path string) []*http.Request {
+	reqs := make([]*http.Request, len(s.servers))
+	for i, server := range s.servers {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			panic(err)
+		}
+		reqs[i] = req
+	}
+	return reqs
+}
+
+// Hits returns the number of requests the i-th server received
+func (s *Servers) Hits(i int) int {
+	return int(atomic.LoadInt32(&s.hits[i]))
+}
+
+// Close closes all the servers
+func (s *Servers) Close() {
+	for _, server := range s.servers {
+		server.Close()
+	}
+}
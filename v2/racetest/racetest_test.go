@@ -0,0 +1,62 @@
+package racetest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mostafa-asg/race/v2"
+)
+
+func TestServers(t *testing.T) {
+	servers := NewServers(
+		Profile{Latency: time.Second, Body: []byte("slow")},
+		Profile{Body: []byte("fast")},
+		Profile{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable},
+	)
+	defer servers.Close()
+
+	req, _ := http.NewRequest("GET", "http://placeholder/", nil)
+	res, err := race.BetweenTargets(req, servers.Targets()[:2]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "fast" {
+		t.Fatalf("Expected the fast server to win, got %q", body)
+	}
+	if servers.Hits(1) != 1 {
+		t.Fatalf("Expected 1 hit, got %d", servers.Hits(1))
+	}
+
+	res, err = http.Get(servers.URLs()[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected an error status, got %d", res.StatusCode)
+	}
+}
+
+func TestServersBandwidth(t *testing.T) {
+	servers := NewServers(Profile{Body: make([]byte, 300), Bandwidth: 1000})
+	defer servers.Close()
+
+	start := time.Now()
+	res, err := http.DefaultClient.Do(servers.Requests("/")[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	if len(body) != 300 {
+		t.Fatalf("Expected the whole body, got %d bytes", len(body))
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("Expected the body to be limited by the bandwidth, took %s", elapsed)
+	}
+}
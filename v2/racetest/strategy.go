@@ -0,0 +1,215 @@
+package racetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mostafa-asg/race/v2"
+)
+
+// RunStrategyTests checks that the strategy satisfies the invariants of race.Strategy:
+// it returns a subset of the targets without duplicates, it doesn't modify the given slice,
+// it's safe for concurrent use, and the races using it behave when all the targets fail,
+// tie or are canceled mid-flight. The strategy must keep at least one target of a race
+func RunStrategyTests(t *testing.T, s race.Strategy) {
+	t.Run("ZeroTargets", func(t *testing.T) {
+		if ordered := s.Order(nil); len(ordered) != 0 {
+			t.Fatalf("Expected no targets, got %d", len(ordered))
+		}
+	})
+
+	t.Run("Subset", func(t *testing.T) {
+		for n := 1; n <= 8; n++ {
+			targets := requests(n)
+			given := append([]*http.Request(nil), targets...)
+			checkOrder(t, targets, s.Order(targets))
+			for i := range targets {
+				if targets[i] != given[i] {
+					t.Fatal("Expected the given targets not to be modified")
+				}
+			}
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				targets := requests(n)
+				for j := 0; j < 50; j++ {
+					observe(s, s.Order(targets), 0, time.Millisecond)
+				}
+			}(i + 1)
+		}
+		wg.Wait()
+	})
+
+	t.Run("AllFail", func(t *testing.T) {
+		r := race.New(race.WithStrategy(s))
+		defer r.Close()
+
+		if res, err := r.Between(closedRequests(t, 3)...); err == nil {
+			res.Body.Close()
+			t.Fatal("Expected the race to fail")
+		}
+	})
+
+	t.Run("AllTie", func(t *testing.T) {
+		servers := NewServers(Profile{Body: []byte("tie")}, Profile{Body: []byte("tie")}, Profile{Body: []byte("tie")})
+		defer servers.Close()
+
+		r := race.New(race.WithStrategy(s))
+		defer r.Close()
+
+		for i := 0; i < 10; i++ {
+			res, err := r.Between(servers.Requests("/")...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			if string(body) != "tie" {
+				t.Fatalf("Unexpected body %q", body)
+			}
+		}
+	})
+
+	t.Run("CanceledMidFlight", func(t *testing.T) {
+		servers := NewServers(Profile{Latency: 10 * time.Second}, Profile{Latency: 10 * time.Second})
+		defer servers.Close()
+
+		r := race.New(race.WithStrategy(s))
+		time.AfterFunc(50*time.Millisecond, func() { r.Close() })
+
+		res, err := r.Between(servers.Requests("/")...)
+		if err == nil {
+			res.Body.Close()
+			t.Fatal("Expected the race to be canceled")
+		}
+		if !errors.Is(err, race.ErrClosed) && !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected the race to be canceled, got %v", err)
+		}
+	})
+}
+
+// FuzzStrategy fuzzes the strategy with the number of targets and the winners of the races
+// reported to it, checking after every race that it returns a subset of the targets without
+// duplicates and doesn't modify the given slice
+func FuzzStrategy(f *testing.F, s race.Strategy) {
+	f.Add(uint8(0), []byte{})
+	f.Add(uint8(1), []byte{0, 0})
+	f.Add(uint8(3), []byte{0, 1, 2, 255})
+	f.Add(uint8(8), []byte{7, 255, 3, 3, 0})
+
+	f.Fuzz(func(t *testing.T, n uint8, winners []byte) {
+		targets := requests(int(n % 17))
+		given := append([]*http.Request(nil), targets...)
+		for _, winner := range winners {
+			ordered := s.Order(targets)
+			if len(targets) == 0 {
+				if len(ordered) != 0 {
+					t.Fatalf("Expected no targets, got %d", len(ordered))
+				}
+				continue
+			}
+			checkOrder(t, targets, ordered)
+			for i := range targets {
+				if targets[i] != given[i] {
+					t.Fatal("Expected the given targets not to be modified")
+				}
+			}
+
+			// a winner beyond the targets means all of them failed
+			observe(s, ordered, int(winner), time.Duration(winner)*time.Millisecond)
+		}
+	})
+}
+
+func requests(n int) []*http.Request {
+	reqs := make([]*http.Request, n)
+	for i := range reqs {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://target-%d.test/", i), nil)
+		if err != nil {
+			panic(err)
+		}
+		reqs[i] = req
+	}
+	return reqs
+}
+
+// closedRequests returns requests to local addresses nothing listens on, so they fail right away
+func closedRequests(t *testing.T, n int) []*http.Request {
+	reqs := make([]*http.Request, n)
+	for i := range reqs {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Close()
+
+		req, err := http.NewRequest(http.MethodGet, "http://"+l.Addr().String()+"/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reqs[i] = req
+	}
+	return reqs
+}
+
+func checkOrder(t *testing.T, targets, ordered []*http.Request) {
+	t.Helper()
+
+	if len(ordered) == 0 {
+		t.Fatalf("Expected at least one of %d targets", len(targets))
+	}
+	seen := make(map[*http.Request]bool)
+	for _, target := range ordered {
+		if seen[target] {
+			t.Fatalf("Target %s is returned twice", target.URL)
+		}
+		seen[target] = true
+
+		found := false
+		for _, given := range targets {
+			found = found || given == target
+		}
+		if !found {
+			t.Fatalf("Target %s is not one of the given targets", target.URL)
+		}
+	}
+}
+
+// observe reports the attempts to the strategy like a race does, the target at the index of
+// the winner succeeds after the elapsed time and the others fail
+func observe(s race.Strategy, targets []*http.Request, winner int, elapsed time.Duration) {
+	observer, ok := s.(race.AttemptObserver)
+	if !ok {
+		return
+	}
+	releaser, _ := s.(race.ReleaseObserver)
+	for i, target := range targets {
+		observer.Started(target)
+		if i == winner {
+			observer.Finished(target, elapsed, nil)
+		} else {
+			observer.Finished(target, elapsed, errFailed)
+		}
+		if releaser != nil {
+			releaser.Released(target)
+		}
+	}
+	if observer, ok := s.(race.WinnerObserver); ok && winner < len(targets) {
+		observer.Won(targets[winner])
+	}
+}
+
+var errFailed = errors.New("racetest: failed")
@@ -0,0 +1,35 @@
+package racetest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mostafa-asg/race/v2"
+)
+
+func TestBuiltinStrategies(t *testing.T) {
+	key := func(target *http.Request) string { return target.URL.Path }
+
+	strategies := map[string]race.Strategy{
+		"RoundRobin":     race.RoundRobin(),
+		"LeastLoaded":    race.LeastLoaded(),
+		"EpsilonGreedy":  race.EpsilonGreedy(0.1, 2),
+		"Explore":        race.Explore(0.1, 2),
+		"Affinity":       race.Affinity(key, race.RoundRobin()),
+		"ConsistentHash": race.ConsistentHash(key, 1),
+		"FailureDomains": race.FailureDomains(key, race.LeastLoaded()),
+	}
+	for name, s := range strategies {
+		t.Run(name, func(t *testing.T) {
+			RunStrategyTests(t, s)
+		})
+	}
+}
+
+func FuzzLeastLoaded(f *testing.F) {
+	FuzzStrategy(f, race.LeastLoaded())
+}
+
+func FuzzEpsilonGreedy(f *testing.F) {
+	FuzzStrategy(f, race.EpsilonGreedy(0.1, 2))
+}
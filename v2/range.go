@@ -0,0 +1,150 @@
+package race
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeIgnored is the error of the attempts answering a ranged request with the whole content
+// when the range is required, see WithRangeCheck
+var ErrRangeIgnored = errors.New("race: target ignored the range")
+
+// ErrRangeMismatch is the error of the attempts whose Content-Range doesn't match the requested range
+var ErrRangeMismatch = errors.New("race: content range doesn't match the requested range")
+
+// WithRangeCheck validates the answers of the requests with a Range header before they can win,
+// a partial content must match the requested range or the attempt fails with ErrRangeMismatch.
+// If the range is required the targets answering with the whole content fail with ErrRangeIgnored,
+// otherwise they can win and the caller must check for 206. Multiple ranges aren't validated
+func WithRangeCheck(required bool) Option {
+	return func(race *Race) {
+		race.rangeCheck = &rangeCheck{required: required}
+	}
+}
+
+type rangeCheck struct {
+	required bool
+}
+
+// byteRange is a range of bytes, start is negative for a suffix of length end
+// and end is negative for a range to the end of the content
+type byteRange struct {
+	start, end int64
+}
+
+// checkRange returns the error of the answer of the ranged request, its body is closed on error
+func (race *Race) checkRange(req *http.Request, res *http.Response) error {
+	if race.rangeCheck == nil {
+		return nil
+	}
+	requested, ok := parseRange(req.Header.Get("Range"))
+	if !ok {
+		return nil
+	}
+
+	var err error
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		err = requested.check(res)
+	case http.StatusOK:
+		if race.rangeCheck.required {
+			err = ErrRangeIgnored
+		}
+	}
+	if err != nil {
+		res.Body.Close()
+	}
+	return err
+}
+
+// check returns an error if the partial content isn't the range
+func (r byteRange) check(res *http.Response) error {
+	start, end, total, ok := parseContentRange(res.Header.Get("Content-Range"))
+	if !ok {
+		return fmt.Errorf("%w: malformed Content-Range %q", ErrRangeMismatch, res.Header.Get("Content-Range"))
+	}
+
+	var valid bool
+	switch {
+	case r.start < 0:
+		valid = end-start+1 <= r.end && (total < 0 || end == total-1)
+	case r.end < 0:
+		valid = start == r.start
+	default:
+		valid = start == r.start && end <= r.end
+	}
+	if res.ContentLength >= 0 && res.ContentLength != end-start+1 {
+		valid = false
+	}
+	if !valid {
+		return fmt.Errorf("%w: got %q", ErrRangeMismatch, res.Header.Get("Content-Range"))
+	}
+	return nil
+}
+
+// parseRange parses a Range header of a single range of bytes
+func parseRange(header string) (byteRange, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return byteRange{}, false
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return byteRange{}, false
+	}
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix <= 0 {
+			return byteRange{}, false
+		}
+		return byteRange{start: -1, end: suffix}, true
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return byteRange{}, false
+	}
+	if last == "" {
+		return byteRange{start: start, end: -1}, true
+	}
+	end, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || end < start {
+		return byteRange{}, false
+	}
+	return byteRange{start: start, end: end}, true
+}
+
+// parseContentRange parses a Content-Range header, total is -1 if it's unknown
+func parseContentRange(header string) (start, end, total int64, ok bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, 0, 0, false
+	}
+	span, size, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, 0, false
+	}
+	first, last, ok := strings.Cut(span, "-")
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	end, err = strconv.ParseInt(last, 10, 64)
+	if err != nil || end < start {
+		return 0, 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil || end >= total {
+			return 0, 0, 0, false
+		}
+	}
+	return start, end, total, true
+}
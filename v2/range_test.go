@@ -0,0 +1,87 @@
+package race
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRangeCheck(t *testing.T) {
+	content := []byte("0123456789")
+	ignoringServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer ignoringServer.Close()
+
+	wrongServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes 0-3/10")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(content[:4])
+	}))
+	defer wrongServer.Close()
+
+	rangeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	defer rangeServer.Close()
+
+	ranged := func(url string) *http.Request {
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set("Range", "bytes=2-5")
+		return req
+	}
+
+	res, err := New(WithRangeCheck(true)).Between(ranged(ignoringServer.URL), ranged(wrongServer.URL), ranged(rangeServer.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusPartialContent || string(body) != "2345" {
+		t.Fatalf("Expected the requested range, got %d %q", res.StatusCode, body)
+	}
+
+	_, err = New(WithRangeCheck(true)).Between(ranged(ignoringServer.URL))
+	if !errors.Is(err, ErrRangeIgnored) {
+		t.Fatalf("Expected the range to be required, got %v", err)
+	}
+	_, err = New(WithRangeCheck(false)).Between(ranged(wrongServer.URL))
+	if !errors.Is(err, ErrRangeMismatch) {
+		t.Fatalf("Expected a mismatch, got %v", err)
+	}
+
+	res, err = New(WithRangeCheck(false)).Between(ranged(ignoringServer.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the whole content, got %d", res.StatusCode)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header string
+		r      byteRange
+		ok     bool
+	}{
+		{"bytes=0-99", byteRange{0, 99}, true},
+		{"bytes=100-", byteRange{100, -1}, true},
+		{"bytes=-50", byteRange{-1, 50}, true},
+		{"bytes=0-1,5-6", byteRange{}, false},
+		{"bytes=9-1", byteRange{}, false},
+		{"items=0-1", byteRange{}, false},
+	}
+	for _, test := range tests {
+		r, ok := parseRange(test.header)
+		if r != test.r || ok != test.ok {
+			t.Errorf("%q: expected %v %t, got %v %t", test.header, test.r, test.ok, r, ok)
+		}
+	}
+}
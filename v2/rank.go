@@ -0,0 +1,70 @@
+package race
+
+import (
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Rank is the standing of a host among the targets of a Race, see Rank
+type Rank struct {
+	Host string
+	// Latency is the exponentially weighted moving average of the latency of the successful attempts
+	Latency time.Duration
+	// ErrorRate is the exponentially weighted moving average of the failures, between 0 and 1
+	ErrorRate float64
+	// Score is the expected cost of an attempt, the latency plus the error rate of the
+	// cost of a failure, the lower the better
+	Score time.Duration
+}
+
+// Rank returns the hosts the Race made attempts to, from the best to the worst score.
+// The attempts are only recorded with WithRankOrdering or WithStandby
+func (race *Race) Rank() []Rank {
+	l := race.ranks
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ranking := make([]Rank, 0, len(l.errorRate))
+	for host, rate := range l.errorRate {
+		ranking = append(ranking, Rank{Host: host, Latency: l.avg[host], ErrorRate: rate, Score: l.score(host)})
+	}
+	sort.Slice(ranking, func(i, j int) bool {
+		if ranking[i].Score != ranking[j].Score {
+			return ranking[i].Score < ranking[j].Score
+		}
+		return ranking[i].Host < ranking[j].Host
+	})
+	return ranking
+}
+
+// WithRankOrdering orders the targets of the races by the score of their host, see Rank, the hosts that
+// have never been observed come first so they get a chance. It refines the order of the Strategy set
+// by the options before it, which is also notified of the attempts
+func WithRankOrdering() Option {
+	return func(race *Race) {
+		race.recordRanks()
+		race.strategy = &ranked{chain: chain{next: race.strategy}, ranks: race.ranks}
+	}
+}
+
+// recordRanks makes the Race record the latency and the error rate of the hosts of its attempts
+func (race *Race) recordRanks() {
+	if race.ranks == nil {
+		race.ranks = newLatencies()
+	}
+}
+
+type ranked struct {
+	chain
+	ranks *latencies
+}
+
+func (r *ranked) Order(targets []*http.Request) []*http.Request {
+	// sorted doesn't modify the slice of the next strategy
+	return r.ranks.sorted(r.order(targets))
+}
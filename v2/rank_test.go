@@ -0,0 +1,75 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRank(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	r := New(WithRankOrdering())
+	defer r.Close()
+
+	host := func(server *httptest.Server) string {
+		u, _ := url.Parse(server.URL)
+		return u.Host
+	}
+	for _, server := range []*httptest.Server{slow, fast} {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		res, err := r.Between(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	failing, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	r.Between(failing)
+
+	ranking := r.Rank()
+	if len(ranking) != 3 || ranking[0].Host != host(fast) || ranking[1].Host != host(slow) || ranking[2].Host != failing.URL.Host {
+		t.Fatalf("Expected the fast, the slow and the failing host, got %+v", ranking)
+	}
+	if ranking[2].ErrorRate != 1 || ranking[0].ErrorRate != 0 || ranking[1].Latency < 20*time.Millisecond {
+		t.Fatalf("Unexpected ranks %+v", ranking)
+	}
+
+	req1, _ := http.NewRequest("GET", slow.URL, nil)
+	req2, _ := http.NewRequest("GET", fast.URL, nil)
+	result, err := r.BetweenResult(context.Background(), req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.Response.Body.Close()
+	if result.Request != req2 || result.Index != 0 {
+		t.Fatalf("Expected the fast host to be the primary, got %s at %d", result.Request.URL, result.Index)
+	}
+}
+
+func TestRank_Disabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	r := New()
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if ranking := r.Rank(); len(ranking) != 0 {
+		t.Fatalf("Expected the attempts not to be ranked without WithRankOrdering, got %+v", ranking)
+	}
+}
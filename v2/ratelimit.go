@@ -0,0 +1,29 @@
+package race
+
+import (
+	"errors"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimited is returned for an attempt that was skipped because
+// the rate limiter of its target had no token
+var ErrRateLimited = errors.New("race: target is rate limited")
+
+// WithRateLimit attaches a rate limiter to the target host, so the races never exceed
+// the QPS of the target. Attempts that can't acquire a token are skipped with
+// ErrRateLimited instead of waiting, and the race is decided by the other targets
+func WithRateLimit(host string, limiter *rate.Limiter) Option {
+	return func(race *Race) {
+		if race.limiters == nil {
+			race.limiters = make(map[string]*rate.Limiter)
+		}
+		race.limiters[host] = limiter
+	}
+}
+
+// allow reports whether the target host has a token for a new attempt
+func (race *Race) allow(host string) bool {
+	limiter, ok := race.limiters[host]
+	return !ok || limiter.Allow()
+}
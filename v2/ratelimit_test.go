@@ -0,0 +1,55 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimit(t *testing.T) {
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("limited"))
+	}))
+	defer limited.Close()
+
+	free := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("free"))
+	}))
+	defer free.Close()
+
+	u, err := url.Parse(limited.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a single token which is never refilled
+	r := New(WithRateLimit(u.Host, rate.NewLimiter(0, 1)))
+
+	req, err := http.NewRequest("GET", limited.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	_, err = r.Between(req)
+	if Classify(err) != FailureRateLimited {
+		t.Fatalf("Expected the attempt to be rate limited, got %v", err)
+	}
+
+	fallback, err := http.NewRequest("GET", free.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = r.Between(req, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
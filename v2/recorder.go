@@ -0,0 +1,165 @@
+package race
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Record is the timeline of a race
+type Record struct {
+	ID    string    `json:"id"`
+	Start time.Time `json:"start"`
+	// Elapsed is the time until the race returned, the losers may finish later
+	Elapsed time.Duration `json:"elapsed"`
+	// Winner is the index of the winner, -1 if there is none
+	Winner int `json:"winner"`
+	// Ties are the other attempts whose responses arrived at nearly the same time as the winner's
+	Ties     []int           `json:"ties,omitempty"`
+	Attempts []AttemptRecord `json:"attempts"`
+}
+
+// AttemptRecord is the timeline of an attempt of a race,
+// the requests that were never started are missing
+type AttemptRecord struct {
+	// Index is the position of the request in the race, the first one is the primary
+	Index  int    `json:"index"`
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Start is the time since the start of the race
+	Start time.Duration `json:"start"`
+	// Elapsed is the time until the response headers arrived or the attempt failed
+	Elapsed    time.Duration `json:"elapsed"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Err        string        `json:"error,omitempty"`
+
+	res *http.Response
+}
+
+type flightRecorder struct {
+	mu      sync.Mutex
+	records []Record
+	// next is the oldest record once the buffer is full
+	next int
+}
+
+// WithFlightRecorder keeps the timelines of the last n races, they can be
+// read by FlightRecords or DebugHandler to investigate latency spikes after the fact
+func WithFlightRecorder(n int) Option {
+	return func(race *Race) {
+		if n > 0 {
+			race.recorder = &flightRecorder{records: make([]Record, 0, n)}
+		}
+	}
+}
+
+// FlightRecords returns the recorded races, the oldest first.
+// A race is recorded once all of its attempts are finished
+func (race *Race) FlightRecords() []Record {
+	if race.recorder == nil {
+		return nil
+	}
+	return race.recorder.all()
+}
+
+// DebugHandler serves the recorded races as JSON
+func (race *Race) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		records := race.FlightRecords()
+		if records == nil {
+			records = []Record{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(records)
+	})
+}
+
+func (r *flightRecorder) add(record Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.records) < cap(r.records) {
+		r.records = append(r.records, record)
+		return
+	}
+	r.records[r.next] = record
+	r.next = (r.next + 1) % len(r.records)
+}
+
+func (r *flightRecorder) all() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records := make([]Record, 0, len(r.records))
+	records = append(records, r.records[r.next:]...)
+	return append(records, r.records[:r.next]...)
+}
+
+// recording reports whether the timelines of the races are kept or sent to a sink
+func (race *Race) recording() bool {
+	return race.recorder != nil || race.recordSink != nil
+}
+
+func (c *contest) recordAttempt(index int, req *http.Request, start time.Time, res *http.Response, err error) {
+	if !c.race.recording() {
+		return
+	}
+
+	attempt := AttemptRecord{
+		Index:   index,
+		Method:  req.Method,
+		URL:     req.URL.Redacted(),
+		Start:   start.Sub(c.started),
+		Elapsed: time.Since(start),
+		res:     res,
+	}
+	if res != nil {
+		attempt.StatusCode = res.StatusCode
+	}
+	if err != nil {
+		attempt.Err = err.Error()
+	}
+
+	c.mu.Lock()
+	c.timeline = append(c.timeline, attempt)
+	c.mu.Unlock()
+}
+
+// reportRecord must be called once all the attempts are finished
+func (c *contest) reportRecord() {
+	if !c.race.recording() {
+		return
+	}
+
+	c.mu.Lock()
+	record := Record{
+		ID:       c.id,
+		Start:    c.started,
+		Elapsed:  c.elapsed,
+		Winner:   -1,
+		Attempts: c.timeline,
+	}
+	for i, attempt := range c.timeline {
+		if attempt.res != nil && attempt.res == c.won {
+			record.Winner = attempt.Index
+		}
+		// don't keep the responses alive
+		c.timeline[i].res = nil
+	}
+	c.mu.Unlock()
+
+	sort.Slice(record.Attempts, func(i, j int) bool {
+		return record.Attempts[i].Index < record.Attempts[j].Index
+	})
+	if record.Winner >= 0 {
+		record.Ties = c.race.ties(record.Attempts, record.Winner)
+	}
+	if c.race.recorder != nil {
+		c.race.recorder.add(record)
+	}
+	if c.race.recordSink != nil {
+		c.race.recordSink.Write(record)
+	}
+}
@@ -0,0 +1,82 @@
+package race
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowServer.Close()
+
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer fastServer.Close()
+
+	r := New(WithFlightRecorder(2))
+	for i := 0; i < 3; i++ {
+		req1, _ := http.NewRequest("GET", slowServer.URL, nil)
+		req2, _ := http.NewRequest("GET", fastServer.URL, nil)
+		res, err := r.Between(req1, req2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	r.Close()
+
+	records := r.FlightRecords()
+	if len(records) != 2 {
+		t.Fatalf("Expected the last 2 races, got %d", len(records))
+	}
+	if !records[0].Start.Before(records[1].Start) {
+		t.Fatal("Expected the oldest race first")
+	}
+
+	record := records[1]
+	if record.Winner != 1 {
+		t.Fatalf("Expected the fast server to win, got %d", record.Winner)
+	}
+	if len(record.Attempts) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(record.Attempts))
+	}
+	if record.Attempts[1].StatusCode != http.StatusAccepted {
+		t.Fatalf("Unexpected status %d", record.Attempts[1].StatusCode)
+	}
+	if record.Attempts[0].Err == "" {
+		t.Fatal("Expected the loser to be canceled")
+	}
+
+	w := httptest.NewRecorder()
+	r.DebugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	var dumped []Record
+	if err := json.NewDecoder(w.Body).Decode(&dumped); err != nil {
+		t.Fatal(err)
+	}
+	if len(dumped) != 2 {
+		t.Fatalf("Expected 2 dumped races, got %d", len(dumped))
+	}
+}
+
+func TestFlightRecorderFailedRace(t *testing.T) {
+	r := New(WithFlightRecorder(1))
+	req, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	if _, err := r.Between(req); err == nil {
+		t.Fatal("Expected the race to fail")
+	}
+	r.Close()
+
+	records := r.FlightRecords()
+	if len(records) != 1 || records[0].Winner != -1 {
+		t.Fatalf("Expected a race without a winner, got %+v", records)
+	}
+}
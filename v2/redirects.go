@@ -0,0 +1,90 @@
+package race
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// WithRedirectCache makes the Race remember permanent redirects (301 and 308)
+// and send the subsequent requests of the same URL directly to the new location,
+// so a mirror that has permanently moved doesn't pay the redirect hop on every race.
+// A remembered location is forgotten as soon as a request to it fails
+func WithRedirectCache() Option {
+	return func(race *Race) {
+		race.redirects = make(map[string]*url.URL)
+	}
+}
+
+// cachedRedirect returns the request rewritten to the remembered location of its URL
+func (race *Race) cachedRedirect(key string, req *http.Request) *http.Request {
+	if race.redirects == nil {
+		return req
+	}
+
+	race.mu.Lock()
+	location, ok := race.redirects[key]
+	race.mu.Unlock()
+	if !ok {
+		return req
+	}
+
+	redirected := req.Clone(req.Context())
+	redirected.URL = location
+	redirected.Host = location.Host
+	return redirected
+}
+
+// rememberRedirect stores the final location of the response if all the
+// redirects it followed were permanent
+func (race *Race) rememberRedirect(key string, res *http.Response) {
+	// redirects that were not followed because CheckRedirect returned
+	// http.ErrUseLastResponse have no previous response
+	if race.redirects == nil || res.Request == nil || res.Request.Response == nil {
+		return
+	}
+
+	for r := res.Request; r.Response != nil; r = r.Response.Request {
+		switch r.Response.StatusCode {
+		case http.StatusPermanentRedirect:
+		case http.StatusMovedPermanently:
+			// net/http turns everything but GET and HEAD into GET after a 301
+			if m := r.Response.Request.Method; m != http.MethodGet && m != http.MethodHead {
+				return
+			}
+		default:
+			return
+		}
+	}
+
+	race.mu.Lock()
+	race.redirects[key] = res.Request.URL
+	race.mu.Unlock()
+}
+
+func (race *Race) forgetRedirect(key string) {
+	if race.redirects == nil {
+		return
+	}
+
+	race.mu.Lock()
+	delete(race.redirects, key)
+	race.mu.Unlock()
+}
+
+// UseLastResponse is a CheckRedirect function that doesn't follow redirects,
+// the redirect response itself takes part in the race like any other response
+func UseLastResponse(req *http.Request, via []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// WithCheckRedirect overrides the CheckRedirect of the http client for the races
+// of this Race only, the given client is not modified.
+// When the function returns http.ErrUseLastResponse the redirect response is a candidate
+// of the race, it's neither an error nor remembered by WithRedirectCache
+func WithCheckRedirect(checkRedirect func(req *http.Request, via []*http.Request) error) Option {
+	return func(race *Race) {
+		client := *race.client
+		client.CheckRedirect = checkRedirect
+		race.client = &client
+	}
+}
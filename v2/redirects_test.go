@@ -0,0 +1,118 @@
+package race
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRedirectCache(t *testing.T) {
+	newServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("moved"))
+	}))
+	defer newServer.Close()
+
+	var hits int32
+	oldServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.Redirect(w, r, newServer.URL+r.URL.Path, http.StatusPermanentRedirect)
+	}))
+	defer oldServer.Close()
+
+	r := New(WithRedirectCache())
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest("GET", oldServer.URL+"/file", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := r.Between(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != "moved" {
+			t.Fatalf("Expected the body of the new location, got %q", body)
+		}
+	}
+
+	if hits := atomic.LoadInt32(&hits); hits != 1 {
+		t.Fatalf("Expected the old location to be hit once, got %d", hits)
+	}
+}
+
+func TestRedirectCache_Temporary(t *testing.T) {
+	newServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer newServer.Close()
+
+	var hits int32
+	oldServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.Redirect(w, r, newServer.URL, http.StatusFound)
+	}))
+	defer oldServer.Close()
+
+	r := New(WithRedirectCache())
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("GET", oldServer.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := r.Between(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	if hits := atomic.LoadInt32(&hits); hits != 2 {
+		t.Fatalf("Expected temporary redirects not to be cached, got %d hits", hits)
+	}
+}
+
+func TestCheckRedirect_UseLastResponse(t *testing.T) {
+	var newHits int32
+	newServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&newHits, 1)
+	}))
+	defer newServer.Close()
+
+	oldServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, newServer.URL, http.StatusPermanentRedirect)
+	}))
+	defer oldServer.Close()
+
+	client := &http.Client{}
+	r := NewWithClient(client, WithCheckRedirect(UseLastResponse), WithRedirectCache())
+	if client.CheckRedirect != nil {
+		t.Fatal("Expected the given client not to be modified")
+	}
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("GET", oldServer.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := r.Between(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusPermanentRedirect {
+			t.Fatalf("Expected the redirect response to win, got %d", res.StatusCode)
+		}
+	}
+
+	if atomic.LoadInt32(&newHits) != 0 {
+		t.Fatal("Expected the redirect not to be followed nor cached")
+	}
+}
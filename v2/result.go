@@ -0,0 +1,73 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Result is the winner of a race and how it won
+type Result struct {
+	Response *http.Response
+	// Index is the position of the winner in the race, after the Strategy ordered the requests
+	Index int
+	// Request is the winning request as it was given
+	Request *http.Request
+	// Elapsed is the time until the response headers of the winner arrived
+	Elapsed time.Duration
+	// Failed is the number of attempts that failed before the winner arrived
+	Failed int
+	// ID is the ID of the race
+	ID string
+	// Err is the error of the race when it failed, the other fields are then empty except ID
+	Err error
+}
+
+// BetweenResult is like BetweenContext but returns the Result of the race,
+// identical races are not coalesced since their results differ
+func (race *Race) BetweenResult(ctx context.Context, reqs ...*http.Request) (Result, error) {
+	return race.betweenResult(ctx, reqs)
+}
+
+// FirstThenStartResult is like FirstThenStartContext but returns the Result of the race
+func (race *Race) FirstThenStartResult(ctx context.Context, first *http.Request, timeout time.Duration, reqs ...*http.Request) (Result, error) {
+	return race.primariesThenStartResult(ctx, []*http.Request{first}, timeout, reqs)
+}
+
+// BetweenResult is like BetweenContext but returns the Result of the race
+func BetweenResult(ctx context.Context, reqs ...*http.Request) (Result, error) {
+	return New().BetweenResult(ctx, reqs...)
+}
+
+// BetweenAsync is like Between but returns at once a channel receiving the Result of the race,
+// or its error in Err, and closed after it, so the caller can select on it with other channels.
+// The response of the winner must be closed even if the caller stops waiting for it
+func (race *Race) BetweenAsync(reqs ...*http.Request) <-chan Result {
+	return race.BetweenAsyncContext(context.Background(), reqs...)
+}
+
+// BetweenAsyncContext is like BetweenAsync but the race is canceled when the context is done
+func (race *Race) BetweenAsyncContext(ctx context.Context, reqs ...*http.Request) <-chan Result {
+	results := make(chan Result, 1)
+	go func() {
+		defer close(results)
+		result, _ := race.betweenResult(ctx, reqs)
+		results <- result
+	}()
+	return results
+}
+
+// result returns the Result of the race of the requests won by res
+func (c *contest) result(reqs []*http.Request, res *http.Response, err error) (Result, error) {
+	if err != nil {
+		return Result{ID: c.id, Err: err}, err
+	}
+	return Result{
+		Response: res,
+		Index:    c.winnerIndex,
+		Request:  reqs[c.winnerIndex],
+		Elapsed:  c.wonAfter,
+		Failed:   c.failures,
+		ID:       c.id,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package race
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBetweenResult(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowServer.Close()
+
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("fast"))
+	}))
+	defer fastServer.Close()
+
+	failing, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	slow, _ := http.NewRequest("GET", slowServer.URL, nil)
+	fast, _ := http.NewRequest("GET", fastServer.URL, nil)
+
+	result, err := New().BetweenResult(context.Background(), failing, slow, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Response.Body.Close()
+
+	if result.Index != 2 || result.Request != fast {
+		t.Fatalf("Expected the fast server to win, got %d", result.Index)
+	}
+	if result.Failed != 1 {
+		t.Fatalf("Expected 1 failed attempt, got %d", result.Failed)
+	}
+	if result.Elapsed < 50*time.Millisecond {
+		t.Fatalf("Unexpected elapsed time %s", result.Elapsed)
+	}
+	if result.ID == "" {
+		t.Fatal("Expected the ID of the race")
+	}
+}
+
+func TestFirstThenStartResult(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowServer.Close()
+
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fastServer.Close()
+
+	first, _ := http.NewRequest("GET", slowServer.URL, nil)
+	fallback, _ := http.NewRequest("GET", fastServer.URL, nil)
+
+	result, err := New().FirstThenStartResult(context.Background(), first, 50*time.Millisecond, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Response.Body.Close()
+
+	if result.Index != 1 || result.Request != fallback {
+		t.Fatalf("Expected the fallback to win, got %d", result.Index)
+	}
+}
+
+func TestBetweenAsync(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	results := New().BetweenAsync(req)
+
+	select {
+	case result := <-results:
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+		result.Response.Body.Close()
+	case <-time.After(time.Second):
+		t.Fatal("Expected the result of the race")
+	}
+	if _, ok := <-results; ok {
+		t.Fatal("Expected the channel to be closed after the result")
+	}
+
+	failing, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	if result := <-New().BetweenAsync(failing); result.Err == nil || result.Response != nil {
+		t.Fatal("Expected the error of the race")
+	}
+}
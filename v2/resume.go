@@ -0,0 +1,57 @@
+package race
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrVersionMismatch is the error of the attempts rejected by SameVersion
+var ErrVersionMismatch = errors.New("race: different version of the content")
+
+// SameVersion returns a Validator rejecting the responses that aren't the same version of the content
+// as the given one, so the parts of a download resumed or split across mirrors are byte-identical.
+// A strong ETag of the given response must be equal, otherwise its Last-Modified, and the total
+// length must match when both are known. Without a strong ETag or Last-Modified the version can't be
+// told and every response is rejected, rather than splicing content that may differ
+func SameVersion(res *http.Response) Validator {
+	etag := strongETag(res.Header.Get("ETag"))
+	modified := res.Header.Get("Last-Modified")
+	length := totalLength(res)
+
+	return func(other *http.Response) error {
+		switch {
+		case etag == "" && modified == "":
+			return fmt.Errorf("%w: no strong validator to compare", ErrVersionMismatch)
+		case etag != "" && strongETag(other.Header.Get("ETag")) != etag:
+			return fmt.Errorf("%w: ETag %q instead of %q", ErrVersionMismatch, other.Header.Get("ETag"), etag)
+		case etag == "" && other.Header.Get("Last-Modified") != modified:
+			return fmt.Errorf("%w: Last-Modified %q instead of %q", ErrVersionMismatch, other.Header.Get("Last-Modified"), modified)
+		}
+		if otherLength := totalLength(other); length >= 0 && otherLength >= 0 && otherLength != length {
+			return fmt.Errorf("%w: length %d instead of %d", ErrVersionMismatch, otherLength, length)
+		}
+		return nil
+	}
+}
+
+// strongETag returns the ETag if it's strong, weak ETags don't guarantee identical bytes
+func strongETag(etag string) string {
+	if strings.HasPrefix(etag, "W/") {
+		return ""
+	}
+	return etag
+}
+
+// totalLength returns the length of the whole content of the response, -1 if it's unknown
+func totalLength(res *http.Response) int64 {
+	if res.StatusCode == http.StatusPartialContent {
+		_, _, total, ok := parseContentRange(res.Header.Get("Content-Range"))
+		if !ok {
+			return -1
+		}
+		return total
+	}
+	return res.ContentLength
+}
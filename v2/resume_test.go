@@ -0,0 +1,67 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSameVersion(t *testing.T) {
+	content := "0123456789"
+	mirror := func(etag string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Content-Range", "bytes 5-9/10")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(content[5:]))
+		}))
+	}
+	sameServer := mirror(`"v1"`)
+	defer sameServer.Close()
+	newerServer := mirror(`"v2"`)
+	defer newerServer.Close()
+
+	first := &http.Response{StatusCode: http.StatusOK, ContentLength: 10, Header: http.Header{"Etag": {`"v1"`}}}
+
+	same, _ := http.NewRequest("GET", sameServer.URL, nil)
+	same.Header.Set("Range", "bytes=5-")
+	newer, _ := http.NewRequest("GET", newerServer.URL, nil)
+	newer.Header.Set("Range", "bytes=5-")
+
+	result, err := Run(context.Background(), []Attempt{
+		{Request: newer, Validator: SameVersion(first)},
+		{Request: same, Validator: SameVersion(first)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.Response.Body.Close()
+	if result.Request != same {
+		t.Fatal("Expected the mirror with the same version to win")
+	}
+
+	newer, _ = http.NewRequest("GET", newerServer.URL, nil)
+	if _, err := Run(context.Background(), []Attempt{{Request: newer, Validator: SameVersion(first)}}); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("Expected a version mismatch, got %v", err)
+	}
+}
+
+func TestSameVersionWithoutStrongValidator(t *testing.T) {
+	weak := &http.Response{StatusCode: http.StatusOK, ContentLength: 10, Header: http.Header{"Etag": {`W/"v1"`}}}
+	other := &http.Response{StatusCode: http.StatusOK, ContentLength: 10, Header: http.Header{"Etag": {`W/"v1"`}}}
+	if err := SameVersion(weak)(other); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("Expected weak ETags to be rejected, got %v", err)
+	}
+
+	modified := http.Header{"Last-Modified": {"Mon, 02 Jan 2006 15:04:05 GMT"}}
+	first := &http.Response{StatusCode: http.StatusOK, ContentLength: 10, Header: modified}
+	truncated := &http.Response{StatusCode: http.StatusOK, ContentLength: 8, Header: modified}
+	if err := SameVersion(first)(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := SameVersion(first)(truncated); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("Expected different lengths to be rejected, got %v", err)
+	}
+}
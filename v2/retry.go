@@ -0,0 +1,89 @@
+package race
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// WithConnectionRetry retries an attempt once on a fresh connection when it fails because
+// the server sent an HTTP/2 GOAWAY or the connection was reset or closed before the response,
+// which usually happens with reused connections, before the target is considered failed.
+// Only idempotent requests, or requests with the idempotency key header, are retried.
+// The retry is subject to the attempt budget, the rate limit and the quota of the target
+func WithConnectionRetry() Option {
+	return func(race *Race) {
+		race.connRetry = true
+	}
+}
+
+// send sends the request with the adapter of its scheme or the http client,
+// retrying it on connection errors if enabled
+func (race *Race) send(req *http.Request) (*http.Response, error) {
+	if adapter := race.adapterFor(req); adapter != nil {
+		return adapter.Do(req)
+	}
+
+	client := race.clientFor(req)
+	res, err := client.Do(req)
+	if err == nil || !race.connRetry || req.Context().Err() != nil || !isConnectionError(err) || !race.isIdempotent(req) {
+		return res, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return res, err
+		}
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return res, err
+		}
+		retry.Body = body
+	}
+
+	if spendAttempt(req.Context()) != nil || !race.allow(req.URL.Host) || race.consume(req.URL.Host) != nil {
+		return res, err
+	}
+	retry.Close = true
+	return race.freshClient(client).Do(retry)
+}
+
+// freshClient returns a copy of the client dialing a new connection for every request,
+// so a retry doesn't reuse a pooled connection to the same broken peer. The transports
+// are cloned once per transport, clients with other round trippers are returned as is
+func (race *Race) freshClient(client *http.Client) *http.Client {
+	base, ok := client.Transport.(*http.Transport)
+	if client.Transport == nil {
+		base, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if !ok {
+		return client
+	}
+
+	race.mu.Lock()
+	transport, ok := race.freshTransports[base]
+	if !ok {
+		transport = base.Clone()
+		transport.DisableKeepAlives = true
+		if race.freshTransports == nil {
+			race.freshTransports = make(map[*http.Transport]*http.Transport)
+		}
+		race.freshTransports[base] = transport
+	}
+	race.mu.Unlock()
+
+	fresh := *client
+	fresh.Transport = transport
+	return &fresh
+}
+
+func isConnectionError(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		// the GOAWAY error of the bundled http2 package is not exported
+		strings.Contains(err.Error(), "GOAWAY")
+}
@@ -0,0 +1,143 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestConnectionRetry(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			// close the connection without a response
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := New(WithConnectionRetry()).Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if hits := atomic.LoadInt32(&hits); hits != 2 {
+		t.Fatalf("Expected the attempt to be retried once, got %d hits", hits)
+	}
+}
+
+func TestConnectionRetry_NonIdempotent(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest("POST", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(WithConnectionRetry()).Between(req); err == nil {
+		t.Fatal("Expected the race to fail")
+	}
+
+	if hits := atomic.LoadInt32(&hits); hits != 1 {
+		t.Fatalf("Expected POST not to be retried, got %d hits", hits)
+	}
+}
+
+func TestConnectionRetry_FreshConnection(t *testing.T) {
+	var hits int32
+	var closing atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		closing.Store(r.Close)
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{}}
+	r := NewWithClient(client, WithConnectionRetry())
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if closing.Load() != true {
+		t.Fatal("Expected the retry to close its connection")
+	}
+
+	fresh := r.freshClient(client)
+	transport, ok := fresh.Transport.(*http.Transport)
+	if !ok || !transport.DisableKeepAlives || transport == client.Transport {
+		t.Fatal("Expected the retry to use a transport without keep-alives")
+	}
+	if r.freshClient(client).Transport != transport {
+		t.Fatal("Expected the transport without keep-alives to be reused")
+	}
+}
+
+func TestConnectionRetry_RateLimited(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(WithConnectionRetry(), WithRateLimit(req.URL.Host, rate.NewLimiter(0, 1)))
+	if _, err := r.Between(req); err == nil {
+		t.Fatal("Expected the race to fail")
+	}
+
+	if hits := atomic.LoadInt32(&hits); hits != 1 {
+		t.Fatalf("Expected the retry to be rate limited, got %d hits", hits)
+	}
+}
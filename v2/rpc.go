@@ -0,0 +1,115 @@
+package race
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message is a request or a reply of an RPC over a message broker
+type Message struct {
+	CorrelationID string
+	// Header holds the properties of the message, e.g. its content type
+	Header http.Header
+	Body   []byte
+	// Status is the status of a reply, zero means 200
+	Status int
+}
+
+// RPCAdapter is an Adapter making request/reply RPCs over a message broker, e.g. MQTT or AMQP, so a
+// race can publish the same request to several request topics and the first reply wins. The topic
+// is the host and the path of the target, e.g. amqp://rpc/users.get is published to "rpc/users.get".
+// Every attempt has its own correlation ID and the replies received by the subscriptions of the
+// application must be given to Reply
+type RPCAdapter struct {
+	publish func(ctx context.Context, topic string, msg Message) error
+
+	mu      sync.Mutex
+	pending map[string]chan Message
+}
+
+// NewRPCAdapter returns an adapter publishing the requests by the given function
+func NewRPCAdapter(publish func(ctx context.Context, topic string, msg Message) error) *RPCAdapter {
+	return &RPCAdapter{
+		publish: publish,
+		pending: make(map[string]chan Message),
+	}
+}
+
+// Do publishes the request and waits for its reply until the attempt is canceled
+func (a *RPCAdapter) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	id := newID()
+	replies := make(chan Message, 1)
+	a.mu.Lock()
+	a.pending[id] = replies
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		delete(a.pending, id)
+		a.mu.Unlock()
+	}()
+
+	topic := strings.TrimSuffix(req.URL.Host+req.URL.Path, "/")
+	err := a.publish(req.Context(), topic, Message{CorrelationID: id, Header: req.Header.Clone(), Body: body})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case reply := <-replies:
+		return replyResponse(req, reply), nil
+	case <-req.Context().Done():
+		return nil, context.Cause(req.Context())
+	}
+}
+
+// Reply delivers the reply to the attempt waiting for its correlation ID,
+// it reports whether there was one, the replies to the losers are dropped
+func (a *RPCAdapter) Reply(reply Message) bool {
+	a.mu.Lock()
+	replies, ok := a.pending[reply.CorrelationID]
+	delete(a.pending, reply.CorrelationID)
+	a.mu.Unlock()
+
+	if ok {
+		replies <- reply
+	}
+	return ok
+}
+
+func replyResponse(req *http.Request, reply Message) *http.Response {
+	status := reply.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := reply.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(reply.Body)),
+		ContentLength: int64(len(reply.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,47 @@
+package race
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRPCAdapter(t *testing.T) {
+	var adapter *RPCAdapter
+	delays := map[string]time.Duration{
+		"region-a/users.get": time.Second,
+		"region-b/users.get": 10 * time.Millisecond,
+	}
+	adapter = NewRPCAdapter(func(ctx context.Context, topic string, msg Message) error {
+		go func() {
+			time.Sleep(delays[topic])
+			adapter.Reply(Message{CorrelationID: msg.CorrelationID, Body: []byte(topic + " " + string(msg.Body))})
+		}()
+		return nil
+	})
+
+	r := New(WithAdapter("amqp", adapter), AllowNonIdempotent())
+	defer r.Close()
+
+	req1, _ := http.NewRequest("POST", "amqp://region-a/users.get", nil)
+	req2, _ := http.NewRequest("POST", "amqp://region-b/users.get", nil)
+	req1.Body = ioutil.NopCloser(strings.NewReader("42"))
+	req2.Body = ioutil.NopCloser(strings.NewReader("42"))
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || string(body) != "region-b/users.get 42" {
+		t.Fatalf("Expected the first reply, got %d %q", res.StatusCode, body)
+	}
+
+	if adapter.Reply(Message{CorrelationID: "unknown"}) {
+		t.Fatal("Expected the reply without a pending request to be dropped")
+	}
+}
@@ -0,0 +1,88 @@
+package race
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// ShadowResult is the outcome of a shadow request, see Shadow
+type ShadowResult struct {
+	Request *http.Request
+	// Response is nil if the shadow failed with Err, its body is already drained and closed
+	Response *http.Response
+	Err      error
+	// Elapsed is the time until the response headers arrived or the shadow failed
+	Elapsed time.Duration
+}
+
+// WithShadowCallback sets the function called with the outcome of every shadow request, see Shadow
+func WithShadowCallback(fn func(ShadowResult)) Option {
+	return func(race *Race) {
+		race.shadowCallback = fn
+	}
+}
+
+// Shadow sends the primary request and returns its response, whatever the shadows do, and sends the
+// shadow requests in the background through the same Race, e.g. to dark launch a new backend. Their
+// outcomes are given to the callback of WithShadowCallback and compared with the primary by WithDiff,
+// they are canceled when the Race is closed
+func (race *Race) Shadow(primary *http.Request, shadows ...*http.Request) (*http.Response, error) {
+	return race.ShadowContext(context.Background(), primary, shadows...)
+}
+
+// ShadowContext is like Shadow but the primary is canceled when the context is done, the shadows aren't
+func (race *Race) ShadowContext(ctx context.Context, primary *http.Request, shadows ...*http.Request) (*http.Response, error) {
+	var p *primaryCapture
+	if race.diff != nil {
+		p = &primaryCapture{done: make(chan struct{})}
+	}
+	for _, shadow := range shadows {
+		race.background.Add(1)
+		go func(shadow *http.Request) {
+			defer race.background.Done()
+			race.shadow(shadow, p)
+		}(shadow)
+	}
+
+	res, err := race.BetweenContext(ctx, primary)
+	if p != nil {
+		p.set(res, race.diff.maxBytes)
+	}
+	return res, err
+}
+
+// Shadow sends the primary request and returns its response, the shadow requests are sent in the background
+func Shadow(primary *http.Request, shadows ...*http.Request) (*http.Response, error) {
+	return New().Shadow(primary, shadows...)
+}
+
+// shadow sends the shadow request and reports its outcome, and its diff with the primary if p isn't nil
+func (race *Race) shadow(req *http.Request, p *primaryCapture) {
+	start := time.Now()
+	res, err := race.do(req.WithContext(withTarget(race.ctx, req)))
+	result := ShadowResult{Request: req, Response: res, Err: err, Elapsed: time.Since(start)}
+
+	var body []byte
+	bodyErr := err
+	if err == nil {
+		if p != nil {
+			body, bodyErr = readShadow(res, race.diff.maxBytes)
+		} else {
+			io.Copy(ioutil.Discard, res.Body)
+		}
+		res.Body.Close()
+	}
+
+	if race.shadowCallback != nil {
+		race.shadowCallback(result)
+	}
+	if p != nil {
+		if bodyErr != nil {
+			res = nil
+		}
+		race.reportDiff(p, res, body, bodyErr)
+	}
+}
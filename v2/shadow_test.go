@@ -0,0 +1,48 @@
+package race
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShadow(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("legacy"))
+	}))
+	defer primary.Close()
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer shadow.Close()
+
+	results := make(chan ShadowResult, 1)
+	r := New(WithShadowCallback(func(result ShadowResult) {
+		results <- result
+	}))
+	defer r.Close()
+
+	req1, _ := http.NewRequest("GET", primary.URL, nil)
+	req2, _ := http.NewRequest("GET", shadow.URL, nil)
+	res, err := r.Shadow(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "legacy" {
+		t.Fatalf("Expected the response of the primary although the shadow was faster, got %q", body)
+	}
+
+	select {
+	case result := <-results:
+		if result.Err != nil || result.Response.StatusCode != http.StatusTeapot || result.Request != req2 {
+			t.Fatalf("Unexpected outcome of the shadow %+v", result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the outcome of the shadow")
+	}
+}
@@ -0,0 +1,57 @@
+package race
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// RecordSink receives the Record of every race, e.g. to feed an offline analysis pipeline
+// through a queue. Write is called in the background once all the attempts of the race are
+// finished, it must not keep the race waiting for long
+type RecordSink interface {
+	Write(record Record)
+}
+
+// RecordSinkFunc is a function used as a RecordSink, e.g. one producing messages to Kafka
+type RecordSinkFunc func(record Record)
+
+// Write calls f
+func (f RecordSinkFunc) Write(record Record) {
+	f(record)
+}
+
+// WithRecordSink sends the Record of every race to the sink
+func WithRecordSink(sink RecordSink) Option {
+	return func(race *Race) {
+		race.recordSink = sink
+	}
+}
+
+// WebhookSink posts every Record to the URL as JSON, the failed posts are dropped
+type WebhookSink struct {
+	URL string
+	// Client is used to post the records, http.DefaultClient if it's nil
+	Client *http.Client
+}
+
+// Write posts the record
+func (sink *WebhookSink) Write(record Record) {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	client := sink.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Post(sink.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+}
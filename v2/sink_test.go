@@ -0,0 +1,63 @@
+package race
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRecordSink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	records := make(chan Record, 1)
+	r := New(WithRecordSink(RecordSinkFunc(func(record Record) {
+		records <- record
+	})))
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	select {
+	case record := <-records:
+		if record.Winner != 0 || len(record.Attempts) != 1 || record.Attempts[0].URL != server.URL {
+			t.Fatalf("Unexpected record %+v", record)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the race to be recorded")
+	}
+	if r.FlightRecords() != nil {
+		t.Fatal("Expected no flight records without a recorder")
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	records := make(chan Record, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record Record
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			t.Error(err)
+		}
+		records <- record
+	}))
+	defer webhook.Close()
+
+	sink := &WebhookSink{URL: webhook.URL}
+	sink.Write(Record{Winner: 2, Attempts: []AttemptRecord{{Index: 2, StatusCode: http.StatusOK}}})
+
+	select {
+	case record := <-records:
+		if record.Winner != 2 || len(record.Attempts) != 1 || record.Attempts[0].StatusCode != http.StatusOK {
+			t.Fatalf("Unexpected record %+v", record)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the record to be posted")
+	}
+}
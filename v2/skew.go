@@ -0,0 +1,54 @@
+package race
+
+import (
+	"net/http"
+	"time"
+)
+
+// WithVersionSkew records the given response header, e.g. X-Build-Version, from all the
+// attempts of a race that complete, and calls the hook with the value of every host
+// when they disagree. It helps to detect partially rolled-out fleets. The losers run
+// for up to the budget after the winner is returned, like WithLoserCompletion, and
+// the hook is called in its own goroutine once all the attempts of the race are finished
+func WithVersionSkew(header string, budget time.Duration, hook func(versions map[string]string)) Option {
+	return func(race *Race) {
+		race.skewHeader = header
+		race.skewHook = hook
+		if budget > race.loserBudget {
+			race.loserBudget = budget
+		}
+	}
+}
+
+func (c *contest) recordVersion(host string, res *http.Response) {
+	if c.race.skewHook == nil {
+		return
+	}
+
+	version := res.Header.Get(c.race.skewHeader)
+	if version == "" {
+		return
+	}
+
+	c.mu.Lock()
+	if c.versions == nil {
+		c.versions = make(map[string]string)
+	}
+	c.versions[host] = version
+	c.mu.Unlock()
+}
+
+func (c *contest) reportSkew() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var first string
+	for _, version := range c.versions {
+		if first == "" {
+			first = version
+		} else if version != first {
+			c.race.skewHook(c.versions)
+			return
+		}
+	}
+}
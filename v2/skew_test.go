@@ -0,0 +1,63 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVersionSkew(t *testing.T) {
+	server := func(version string, delay time.Duration) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			w.Header().Set("X-Build-Version", version)
+		}))
+	}
+	fast := server("1", 0)
+	defer fast.Close()
+	same := server("1", 20*time.Millisecond)
+	defer same.Close()
+	skewed := server("2", 20*time.Millisecond)
+	defer skewed.Close()
+
+	reported := make(chan map[string]string, 1)
+	r := New(WithVersionSkew("X-Build-Version", time.Second, func(versions map[string]string) {
+		reported <- versions
+	}))
+	defer r.Close()
+
+	between := func(servers ...*httptest.Server) {
+		var reqs []*http.Request
+		for _, server := range servers {
+			req, err := http.NewRequest("GET", server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reqs = append(reqs, req)
+		}
+		res, err := r.Between(reqs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	between(fast, same)
+	select {
+	case versions := <-reported:
+		t.Fatalf("Expected no report when versions agree, got %v", versions)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	between(fast, skewed)
+	select {
+	case versions := <-reported:
+		fastHost, skewedHost := fast.Listener.Addr().String(), skewed.Listener.Addr().String()
+		if versions[fastHost] != "1" || versions[skewedHost] != "2" {
+			t.Fatalf("Unexpected versions %v", versions)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the skew to be reported")
+	}
+}
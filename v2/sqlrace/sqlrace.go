@@ -0,0 +1,48 @@
+// Package sqlrace races read-only queries across database replicas
+package sqlrace
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mostafa-asg/race/v2"
+)
+
+// Rows are the rows of the replica that answered first
+type Rows struct {
+	*sql.Rows
+	// Index is the position of the replica in the race
+	Index int
+
+	cancel context.CancelFunc
+}
+
+// Close closes the rows and releases the query of the replica
+func (rows *Rows) Close() error {
+	defer rows.cancel()
+	return rows.Rows.Close()
+}
+
+// Query runs the query on every replica simultaneously and returns the rows of the first one to answer,
+// the queries of the other replicas are canceled. The query must be read-only, it may run on all the replicas.
+// If all of them fail the errors are aggregated like the ones of a failed race, each one telling its replica
+func Query(ctx context.Context, replicas []*sql.DB, query string, args ...interface{}) (*Rows, error) {
+	fns := make([]func(ctx context.Context) (*sql.Rows, error), len(replicas))
+	for i, db := range replicas {
+		db := db
+		fns[i] = func(ctx context.Context) (*sql.Rows, error) {
+			return db.QueryContext(ctx, query, args...)
+		}
+	}
+
+	rows, index, cancel, err := race.First(ctx, "replica", closeRows, fns...)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{Rows: rows, Index: index, cancel: cancel}, nil
+}
+
+// closeRows closes the rows of a replica answering after the winner
+func closeRows(rows *sql.Rows) {
+	rows.Close()
+}
@@ -0,0 +1,112 @@
+package sqlrace
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// replicaDriver opens replicas named by their DSN, "name:delay", answering the name after the delay
+type replicaDriver struct{}
+
+func (replicaDriver) Open(dsn string) (driver.Conn, error) {
+	name, delay, _ := strings.Cut(dsn, ":")
+	d, err := time.ParseDuration(delay)
+	if err != nil {
+		return nil, err
+	}
+	return &replicaConn{name: name, delay: d}, nil
+}
+
+type replicaConn struct {
+	name  string
+	delay time.Duration
+}
+
+func (c *replicaConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *replicaConn) Close() error              { return nil }
+func (c *replicaConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c *replicaConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.name == "down" {
+		return nil, errors.New("connection refused")
+	}
+	select {
+	case <-time.After(c.delay):
+		return &replicaRows{name: c.name}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type replicaRows struct {
+	name string
+	done bool
+}
+
+func (r *replicaRows) Columns() []string { return []string{"replica"} }
+func (r *replicaRows) Close() error      { return nil }
+
+func (r *replicaRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.name
+	return nil
+}
+
+func init() {
+	sql.Register("replica", replicaDriver{})
+}
+
+func TestQuery(t *testing.T) {
+	var replicas []*sql.DB
+	for _, dsn := range []string{"down:0s", "slow:1s", "fast:10ms"} {
+		db, err := sql.Open("replica", dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		replicas = append(replicas, db)
+	}
+
+	start := time.Now()
+	rows, err := Query(context.Background(), replicas, "SELECT replica")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var name string
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	if err := rows.Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "fast" || rows.Index != 2 {
+		t.Fatalf("Expected the fast replica to answer, got %s", name)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("Expected the slow replica not to be waited for")
+	}
+
+	_, err = Query(context.Background(), replicas[:1], "SELECT replica")
+	if err == nil || !strings.Contains(err.Error(), "replica 0") {
+		t.Fatalf("Expected the error of the replica, got %v", err)
+	}
+	var merr *multierror.Error
+	if !errors.As(err, &merr) || !strings.HasPrefix(err.Error(), "race failed") {
+		t.Fatalf("Expected the errors to be aggregated like the ones of a race, got %v", err)
+	}
+}
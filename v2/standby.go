@@ -0,0 +1,167 @@
+package race
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// standby is the connection kept to the best secondary target
+type standby struct {
+	path     string
+	interval time.Duration
+
+	// target is the scheme and the host of the secondary, via is its Target if any,
+	// changed wakes up the loop checking it
+	target  url.URL
+	via     *Target
+	healthy bool
+	running bool
+	changed chan struct{}
+}
+
+// WithStandby keeps an idle connection to the best secondary target, i.e. the hedge of the last race with
+// the best score, see Rank, or the first hedge as ordered by the Strategy until the hedges are observed, so
+// when a hedge fires it skips DNS, TCP and TLS. The target is checked with a HEAD request for the path when
+// it changes and then every interval, with the client of its Target if any, which also keeps the connection
+// from being closed as idle, see Standby. The targets served by an Adapter aren't kept
+func WithStandby(path string, interval time.Duration) Option {
+	return func(race *Race) {
+		race.standby = &standby{path: path, interval: interval, changed: make(chan struct{}, 1)}
+		race.recordRanks()
+	}
+}
+
+// Standby returns the host of the secondary target kept by WithStandby and whether its last check succeeded
+func (race *Race) Standby() (host string, healthy bool) {
+	if race.standby == nil {
+		return "", false
+	}
+
+	race.mu.Lock()
+	defer race.mu.Unlock()
+	return race.standby.target.Host, race.standby.healthy
+}
+
+// noteSecondary makes the best of the hedges of a race the secondary kept by WithStandby
+func (race *Race) noteSecondary(hedges []*http.Request) {
+	s := race.standby
+	if s == nil {
+		return
+	}
+	req := race.secondary(hedges)
+	if req == nil {
+		return
+	}
+	via, _ := req.Context().Value(targetKey{}).(*Target)
+
+	race.mu.Lock()
+	defer race.mu.Unlock()
+	if s.target.Scheme == req.URL.Scheme && s.target.Host == req.URL.Host && sameClient(s.via, via) {
+		return
+	}
+	s.target = url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host}
+	s.via = via
+	s.healthy = false
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+
+	if !s.running {
+		s.running = true
+		race.background.Add(1)
+		go race.keepStandby()
+	}
+}
+
+// secondary returns the hedge with the best score, the first one if none of them was observed.
+// The hedges served by an Adapter are skipped
+func (race *Race) secondary(hedges []*http.Request) *http.Request {
+	race.ranks.mu.Lock()
+	defer race.ranks.mu.Unlock()
+
+	var best *http.Request
+	var bestScore time.Duration
+	for _, req := range hedges {
+		if race.adapterFor(req) != nil {
+			continue
+		}
+		_, observed := race.ranks.errorRate[req.URL.Host]
+		score := race.ranks.score(req.URL.Host)
+		switch {
+		case best == nil:
+			best = req
+			bestScore = -1
+			if observed {
+				bestScore = score
+			}
+		case observed && (bestScore < 0 || score < bestScore):
+			best, bestScore = req, score
+		}
+	}
+	return best
+}
+
+// sameClient reports whether the targets are sent by the same client, the targets are cloned by race
+func sameClient(a, b *Target) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Client == b.Client && a.TLS == b.TLS
+}
+
+// keepStandby checks the secondary target until the Race is closed
+func (race *Race) keepStandby() {
+	defer race.background.Done()
+	s := race.standby
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.changed:
+		case <-ticker.C:
+		case <-race.ctx.Done():
+			return
+		}
+
+		race.mu.Lock()
+		target, via := s.target, s.via
+		race.mu.Unlock()
+
+		healthy := race.checkStandby(target, via)
+
+		race.mu.Lock()
+		if s.target == target && s.via == via {
+			s.healthy = healthy
+		}
+		race.mu.Unlock()
+	}
+}
+
+// checkStandby sends the HEAD request for the path to the target, it reports whether it succeeded
+func (race *Race) checkStandby(target url.URL, via *Target) bool {
+	ctx, cancel := context.WithTimeout(race.ctx, race.standby.interval)
+	defer cancel()
+	if via != nil {
+		ctx = context.WithValue(ctx, targetKey{}, via)
+	}
+
+	u := target.ResolveReference(&url.URL{Path: race.standby.path})
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+	if err != nil {
+		return false
+	}
+	res, err := race.clientFor(req).Do(req)
+	if err != nil {
+		return false
+	}
+	// the body is drained so the connection goes back to the idle pool
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+	return res.StatusCode >= 200 && res.StatusCode < 300
+}
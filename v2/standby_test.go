@@ -0,0 +1,115 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStandby(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer primary.Close()
+
+	var checks int32
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead && r.URL.Path == "/healthz" {
+			atomic.AddInt32(&checks, 1)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer secondary.Close()
+
+	r := New(WithStandby("/healthz", 20*time.Millisecond))
+	defer r.Close()
+
+	if host, _ := r.Standby(); host != "" {
+		t.Fatalf("Expected no standby before a race, got %s", host)
+	}
+
+	req1, _ := http.NewRequest("GET", primary.URL, nil)
+	req2, _ := http.NewRequest("GET", secondary.URL+"/data", nil)
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&checks) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&checks) < 2 {
+		t.Fatal("Expected the secondary to be checked periodically")
+	}
+
+	u, _ := url.Parse(secondary.URL)
+	if host, healthy := r.Standby(); host != u.Host || !healthy {
+		t.Fatalf("Expected the secondary to be a healthy standby, got %s %v", host, healthy)
+	}
+}
+
+func TestStandby_BestScore(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	primary := httptest.NewServer(handler)
+	defer primary.Close()
+	slow := httptest.NewServer(handler)
+	defer slow.Close()
+	fast := httptest.NewServer(handler)
+	defer fast.Close()
+
+	r := New(WithStandby("/healthz", time.Minute))
+	defer r.Close()
+
+	slowURL, _ := url.Parse(slow.URL)
+	fastURL, _ := url.Parse(fast.URL)
+	r.ranks.observe(slowURL.Host, 200*time.Millisecond, nil)
+	r.ranks.observe(fastURL.Host, 10*time.Millisecond, nil)
+
+	req1, _ := http.NewRequest("GET", primary.URL, nil)
+	req2, _ := http.NewRequest("GET", slow.URL, nil)
+	req3, _ := http.NewRequest("GET", fast.URL, nil)
+	res, err := r.FirstThenStart(req1, time.Minute, req2, req3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if host, _ := r.Standby(); host != fastURL.Host {
+		t.Fatalf("Expected the hedge with the best score to be the standby before any hedge fires, got %s", host)
+	}
+}
+
+func TestStandby_TargetClient(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer primary.Close()
+	secondary := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer secondary.Close()
+
+	r := New(WithStandby("/healthz", 20*time.Millisecond))
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", "http://placeholder/", nil)
+	res, err := r.BetweenTargets(req, Target{URL: primary.URL}, Target{URL: secondary.URL, Client: secondary.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	u, _ := url.Parse(secondary.URL)
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if host, healthy := r.Standby(); host == u.Host && healthy {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Expected the standby to be checked with the client of its target")
+}
@@ -0,0 +1,79 @@
+package race
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Sticky returns a strategy that sends the races only to the target which won the last full race, so the
+// tail latency stays low without multiplying the traffic of every call. The winner is elected again by a
+// full race once the interval elapsed, or as soon as an attempt to it fails. The races whose targets don't
+// include the winner are full races, a Sticky strategy is meant for a single set of targets. The targets
+// are ordered by the next strategy first if it's not nil, it's also notified of the attempts and the winners.
+// Targets are identified by their host
+func Sticky(interval time.Duration, next Strategy) Strategy {
+	return &sticky{chain: chain{next: next}, interval: interval}
+}
+
+type sticky struct {
+	chain
+	interval time.Duration
+
+	mu      sync.Mutex
+	host    string
+	elected time.Time
+}
+
+// winner returns the host of the winner if it's still elected
+func (s *sticky) winner() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.host == "" || time.Since(s.elected) >= s.interval {
+		return "", false
+	}
+	return s.host, true
+}
+
+func (s *sticky) Order(targets []*http.Request) []*http.Request {
+	ordered := s.order(targets)
+
+	host, ok := s.winner()
+	if !ok {
+		return ordered
+	}
+	for _, target := range ordered {
+		if target.URL.Host == host {
+			return []*http.Request{target}
+		}
+	}
+	return ordered
+}
+
+func (s *sticky) Finished(target *http.Request, elapsed time.Duration, err error) {
+	s.chain.Finished(target, elapsed, err)
+	if err == nil || canceled(err) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.host == target.URL.Host {
+		s.host = ""
+	}
+}
+
+func (s *sticky) Won(target *http.Request) {
+	s.chain.Won(target)
+
+	if _, ok := s.winner(); ok {
+		// the race only had the winner
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.host = target.URL.Host
+	s.elected = time.Now()
+}
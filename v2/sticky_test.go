@@ -0,0 +1,84 @@
+package race
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type startCounter struct {
+	n int32
+}
+
+func (s *startCounter) Order(targets []*http.Request) []*http.Request { return targets }
+
+func (s *startCounter) Started(target *http.Request) { atomic.AddInt32(&s.n, 1) }
+
+func (s *startCounter) Finished(target *http.Request, elapsed time.Duration, err error) {}
+
+func TestSticky(t *testing.T) {
+	var fastHits, slowHits int32
+	var failing int32
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fastHits, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			hj, _ := w.(http.Hijacker)
+			conn, _, _ := hj.Hijack()
+			conn.Close()
+		}
+	}))
+	defer fast.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&slowHits, 1)
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	started := &startCounter{}
+	r := New(WithStrategy(Sticky(100*time.Millisecond, started)))
+	defer r.Close()
+
+	between := func() error {
+		req1, _ := http.NewRequest("GET", slow.URL, nil)
+		req2, _ := http.NewRequest("GET", fast.URL, nil)
+		res, err := r.Between(req1, req2)
+		if err == nil {
+			res.Body.Close()
+		}
+		return err
+	}
+
+	for i := 0; i < 4; i++ {
+		if err := between(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if slow, fast := atomic.LoadInt32(&slowHits), atomic.LoadInt32(&fastHits); slow > 1 || fast != 4 {
+		t.Fatalf("Expected the calls after the first race to stick to the winner, got %d slow and %d fast", slow, fast)
+	}
+
+	// the winner is elected again after the interval
+	time.Sleep(100 * time.Millisecond)
+	before := atomic.LoadInt32(&started.n)
+	if err := between(); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&started.n) != before+2 {
+		t.Fatal("Expected a full race once the interval elapsed")
+	}
+
+	// a failure of the winner triggers a full race
+	atomic.StoreInt32(&failing, 1)
+	if err := between(); err == nil {
+		t.Fatal("Expected the call sticking to the failing winner to fail")
+	}
+	before = atomic.LoadInt32(&slowHits)
+	if err := between(); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&slowHits) == before {
+		t.Fatal("Expected a full race after the failure")
+	}
+}
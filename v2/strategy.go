@@ -0,0 +1,184 @@
+package race
+
+import (
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Strategy decides which targets of Between and FirstThenStart are tried and in which order,
+// the first target returned is the primary and the others are the hedges, targets left out
+// are not part of the race. The Target of the requests of BetweenTargets is given by TargetOf.
+// Order must not modify the given slice and must be safe for concurrent use
+type Strategy interface {
+	Order(targets []*http.Request) []*http.Request
+}
+
+// WithStrategy sets the strategy used to choose the primary target
+func WithStrategy(strategy Strategy) Option {
+	return func(race *Race) {
+		race.strategy = strategy
+	}
+}
+
+// order applies the strategy of the race to the targets
+func (race *Race) order(targets []*http.Request) []*http.Request {
+	if race.strategy != nil {
+		targets = race.strategy.Order(targets)
+	}
+	if len(targets) > 0 {
+		race.noteSecondary(targets[1:])
+	}
+	return targets
+}
+
+// RoundRobin returns a strategy that rotates the primary across successive races,
+// so the load of the primary and the cache warmth are spread evenly across the targets
+// instead of always hitting the first one. The hedges behind the primary are shuffled
+// for every race, and the rotation starts from a random target, so different processes
+// don't all start with the same primary
+func RoundRobin() Strategy {
+	return &roundRobin{
+		next: uint64(rand.New(rand.NewSource(time.Now().UnixNano())).Int63()),
+	}
+}
+
+type roundRobin struct {
+	next uint64
+}
+
+func (rr *roundRobin) Order(targets []*http.Request) []*http.Request {
+	if len(targets) == 0 {
+		return targets
+	}
+
+	offset := int((atomic.AddUint64(&rr.next, 1) - 1) % uint64(len(targets)))
+
+	ordered := make([]*http.Request, 0, len(targets))
+	ordered = append(ordered, targets[offset:]...)
+	ordered = append(ordered, targets[:offset]...)
+	hedges := ordered[1:]
+	rand.Shuffle(len(hedges), func(i, j int) {
+		hedges[i], hedges[j] = hedges[j], hedges[i]
+	})
+	return ordered
+}
+
+// AttemptObserver can be implemented by a Strategy that learns from the attempts
+// made by the Race. Finished is called when the response headers arrive or the attempt fails,
+// losers that are canceled at the end of a race finish with context.Canceled
+type AttemptObserver interface {
+	Started(target *http.Request)
+	Finished(target *http.Request, elapsed time.Duration, err error)
+}
+
+// ReleaseObserver can be implemented by a Strategy that tracks the load of the targets.
+// Released is called once an attempt stops using its target, when it fails
+// or when the body of its response is closed
+type ReleaseObserver interface {
+	Released(target *http.Request)
+}
+
+// WinnerObserver can be implemented by a Strategy that learns which target won each race
+type WinnerObserver interface {
+	Won(target *http.Request)
+}
+
+// chain is embedded by the strategies refining the order of the next strategy,
+// the attempts and the winners are forwarded to it
+type chain struct {
+	next Strategy
+}
+
+// order returns the order of the next strategy or a copy of the targets without one
+func (c chain) order(targets []*http.Request) []*http.Request {
+	if c.next != nil {
+		return c.next.Order(targets)
+	}
+	ordered := make([]*http.Request, len(targets))
+	copy(ordered, targets)
+	return ordered
+}
+
+func (c chain) Started(target *http.Request) {
+	if observer, ok := c.next.(AttemptObserver); ok {
+		observer.Started(target)
+	}
+}
+
+func (c chain) Finished(target *http.Request, elapsed time.Duration, err error) {
+	if observer, ok := c.next.(AttemptObserver); ok {
+		observer.Finished(target, elapsed, err)
+	}
+}
+
+func (c chain) Released(target *http.Request) {
+	if observer, ok := c.next.(ReleaseObserver); ok {
+		observer.Released(target)
+	}
+}
+
+func (c chain) Won(target *http.Request) {
+	if observer, ok := c.next.(WinnerObserver); ok {
+		observer.Won(target)
+	}
+}
+
+// LeastLoaded returns a strategy that chooses the target with the fewest in-flight attempts
+// as the primary, approximating least-connections load balancing while keeping the hedge.
+// An attempt is in flight until its body is closed. Targets are identified by their host,
+// and those whose last attempt failed are tried last
+func LeastLoaded() Strategy {
+	return &leastLoaded{
+		inflight:  make(map[string]int),
+		unhealthy: make(map[string]bool),
+	}
+}
+
+type leastLoaded struct {
+	mu        sync.Mutex
+	inflight  map[string]int
+	unhealthy map[string]bool
+}
+
+func (ll *leastLoaded) Order(targets []*http.Request) []*http.Request {
+	ordered := make([]*http.Request, len(targets))
+	copy(ordered, targets)
+
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		hi, hj := ordered[i].URL.Host, ordered[j].URL.Host
+		if ll.unhealthy[hi] != ll.unhealthy[hj] {
+			return !ll.unhealthy[hi]
+		}
+		return ll.inflight[hi] < ll.inflight[hj]
+	})
+
+	return ordered
+}
+
+func (ll *leastLoaded) Started(target *http.Request) {
+	ll.mu.Lock()
+	ll.inflight[target.URL.Host]++
+	ll.mu.Unlock()
+}
+
+func (ll *leastLoaded) Finished(target *http.Request, elapsed time.Duration, err error) {
+	if canceled(err) {
+		return
+	}
+	ll.mu.Lock()
+	ll.unhealthy[target.URL.Host] = err != nil
+	ll.mu.Unlock()
+}
+
+func (ll *leastLoaded) Released(target *http.Request) {
+	ll.mu.Lock()
+	ll.inflight[target.URL.Host]--
+	ll.mu.Unlock()
+}
@@ -0,0 +1,170 @@
+package race
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRoundRobin(t *testing.T) {
+	var targets []*http.Request
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest("GET", "http://localhost", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, req)
+	}
+
+	rr := RoundRobin()
+	primaries := make(map[*http.Request]int)
+	orders := make(map[[3]*http.Request]bool)
+	for i := 0; i < 300; i++ {
+		ordered := rr.Order(targets)
+		if len(ordered) != len(targets) {
+			t.Fatalf("Expected %d targets, got %d", len(targets), len(ordered))
+		}
+		seen := make(map[*http.Request]bool)
+		for _, target := range ordered {
+			seen[target] = true
+		}
+		if len(seen) != len(targets) {
+			t.Fatal("Expected every target once")
+		}
+		primaries[ordered[0]]++
+		orders[[3]*http.Request{ordered[0], ordered[1], ordered[2]}] = true
+	}
+
+	for _, target := range targets {
+		if primaries[target] != 100 {
+			t.Fatalf("Expected every target to be the primary 100 times, got %d", primaries[target])
+		}
+	}
+	// 3 primaries with 2 orders of their hedges each
+	if len(orders) != 6 {
+		t.Fatalf("Expected the hedges to be shuffled, got %d orders", len(orders))
+	}
+
+	if len(rr.Order(nil)) != 0 {
+		t.Fatal("Expected no targets")
+	}
+}
+
+func TestFirstThenStart_Strategy(t *testing.T) {
+	var hits1, hits2 int32
+	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits1, 1)
+	}))
+	defer server1.Close()
+
+	server2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits2, 1)
+	}))
+	defer server2.Close()
+
+	req1, err := http.NewRequest("GET", server1.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req2, err := http.NewRequest("GET", server2.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(WithStrategy(RoundRobin()))
+	for i := 0; i < 4; i++ {
+		// the hedge never starts because the primary answers before the timeout
+		res, err := r.FirstThenStart(req1, 60*time.Second, req2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	if hits1, hits2 := atomic.LoadInt32(&hits1), atomic.LoadInt32(&hits2); hits1 != 2 || hits2 != 2 {
+		t.Fatalf("Expected both targets to be the primary twice, got %d and %d", hits1, hits2)
+	}
+}
+
+func TestLeastLoaded(t *testing.T) {
+	busy, err := http.NewRequest("GET", "http://busy", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idle, err := http.NewRequest("GET", "http://idle", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broken, err := http.NewRequest("GET", "http://broken", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ll := LeastLoaded()
+	observer := ll.(AttemptObserver)
+
+	observer.Started(busy)
+	observer.Started(broken)
+	observer.Finished(broken, time.Millisecond, errors.New("boom"))
+
+	ordered := ll.Order([]*http.Request{broken, busy, idle})
+	if ordered[0] != idle || ordered[1] != busy || ordered[2] != broken {
+		t.Fatal("Expected the idle target first and the broken one last")
+	}
+
+	observer.Finished(busy, time.Millisecond, nil)
+	observer.Started(idle)
+
+	// the body of busy is still streaming
+	ordered = ll.Order([]*http.Request{busy, idle})
+	if ordered[0] != busy {
+		t.Fatal("Expected the order to be kept while both targets are loaded")
+	}
+
+	ll.(ReleaseObserver).Released(busy)
+	ordered = ll.Order([]*http.Request{idle, busy})
+	if ordered[0] != busy {
+		t.Fatal("Expected the target with no in-flight attempts to be the primary")
+	}
+}
+
+func TestLeastLoaded_Body(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	busy, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idle, err := http.NewRequest("GET", "http://idle", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ll := LeastLoaded()
+	r := New(WithStrategy(ll))
+	defer r.Close()
+
+	res, err := r.Between(busy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ordered := ll.Order([]*http.Request{busy, idle}); ordered[0] != idle {
+		t.Fatal("Expected the target to be loaded until the body is closed")
+	}
+
+	res.Body.Close()
+	if ordered := ll.Order([]*http.Request{busy, idle}); ordered[0] != busy {
+		t.Fatal("Expected the target to be released when the body is closed")
+	}
+}
@@ -0,0 +1,77 @@
+package race
+
+import "context"
+
+// Stream is a server stream, e.g. the client of a gRPC server-streaming RPC generated by protoc-gen-go-grpc
+type Stream[T any] interface {
+	Recv() (T, error)
+}
+
+// RacedStream is the stream that delivered its first message first, see FirstStream
+type RacedStream[T any] struct {
+	// Index is the position of the stream in the race
+	Index int
+
+	stream Stream[T]
+	first  *T
+	cancel context.CancelFunc
+}
+
+// Recv returns the first message of the stream and then the next ones
+func (s *RacedStream[T]) Recv() (T, error) {
+	if s.first != nil {
+		msg := *s.first
+		s.first = nil
+		return msg, nil
+	}
+	return s.stream.Recv()
+}
+
+// Close cancels the context of the stream, it must be called once the stream isn't used anymore
+func (s *RacedStream[T]) Close() {
+	s.cancel()
+}
+
+// openedStream is a stream with its first message
+type openedStream[T any] struct {
+	stream Stream[T]
+	first  T
+}
+
+// FirstStream opens the streams simultaneously, e.g. the same server-streaming RPC on several replicas,
+// and returns the first one to deliver its first message. Each stream is opened with its own child of
+// the context, the losers are canceled, and closed with CloseSend if they have it, as soon as the winner
+// is known. If all the streams fail the errors are aggregated like the ones of a failed race
+func FirstStream[T any](ctx context.Context, open ...func(ctx context.Context) (Stream[T], error)) (*RacedStream[T], error) {
+	fns := make([]func(ctx context.Context) (openedStream[T], error), len(open))
+	for i, fn := range open {
+		fn := fn
+		fns[i] = func(ctx context.Context) (openedStream[T], error) {
+			stream, err := fn(ctx)
+			if err != nil {
+				return openedStream[T]{}, err
+			}
+			msg, err := stream.Recv()
+			if err != nil {
+				closeSend(stream)
+				return openedStream[T]{}, err
+			}
+			return openedStream[T]{stream: stream, first: msg}, nil
+		}
+	}
+
+	opened, index, cancel, err := first(ctx, "stream", fns, func(loser openedStream[T]) {
+		closeSend(loser.stream)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RacedStream[T]{Index: index, stream: opened.stream, first: &opened.first, cancel: cancel}, nil
+}
+
+// closeSend closes the sending side of the stream if it has one
+func closeSend[T any](stream Stream[T]) {
+	if closer, ok := stream.(interface{ CloseSend() error }); ok {
+		closer.CloseSend()
+	}
+}
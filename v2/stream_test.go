@@ -0,0 +1,76 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testStream struct {
+	ctx      context.Context
+	delay    time.Duration
+	messages []string
+	closed   *int32
+}
+
+func (s *testStream) Recv() (string, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-s.ctx.Done():
+		return "", s.ctx.Err()
+	}
+	if len(s.messages) == 0 {
+		return "", io.EOF
+	}
+	msg := s.messages[0]
+	s.messages = s.messages[1:]
+	return msg, nil
+}
+
+func (s *testStream) CloseSend() error {
+	atomic.AddInt32(s.closed, 1)
+	return nil
+}
+
+func TestFirstStream(t *testing.T) {
+	var closed int32
+	open := func(delay time.Duration, messages ...string) func(ctx context.Context) (Stream[string], error) {
+		return func(ctx context.Context) (Stream[string], error) {
+			return &testStream{ctx: ctx, delay: delay, messages: messages, closed: &closed}, nil
+		}
+	}
+	failing := func(ctx context.Context) (Stream[string], error) {
+		return nil, errors.New("unavailable")
+	}
+
+	stream, err := FirstStream(context.Background(), failing, open(time.Second, "slow"), open(10*time.Millisecond, "fast 1", "fast 2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	if stream.Index != 2 {
+		t.Fatalf("Expected the fast stream to win, got %d", stream.Index)
+	}
+	for _, expected := range []string{"fast 1", "fast 2"} {
+		if msg, err := stream.Recv(); err != nil || msg != expected {
+			t.Fatalf("Expected %q, got %q %v", expected, msg, err)
+		}
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("Expected the end of the stream, got %v", err)
+	}
+
+	// the slow stream is canceled instead of waiting for its message
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&closed) != 1 {
+		t.Fatal("Expected the losing stream to be closed")
+	}
+
+	if _, err := FirstStream(context.Background(), failing, failing); err == nil {
+		t.Fatal("Expected the race of failing streams to fail")
+	}
+}
@@ -0,0 +1,167 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// ErrTargetSaturated is returned for an attempt whose target already has the
+// maximum number of in-flight requests and the Spill policy is in use, or its queue is full
+var ErrTargetSaturated = errors.New("race: target has reached its maximum concurrent streams")
+
+// ErrQueueTimeout is returned for an attempt that waited in the queue of its target
+// longer than the deadline given to WithStreamQueue
+var ErrQueueTimeout = errors.New("race: timed out waiting for a free stream")
+
+// StreamPolicy decides what happens to an attempt whose target already has
+// the maximum number of in-flight requests
+type StreamPolicy int
+
+const (
+	// Queue makes the attempt wait until the target has a free stream
+	Queue StreamPolicy = iota
+	// Spill fails the attempt immediately with ErrTargetSaturated,
+	// so the race is decided by the other targets
+	Spill
+)
+
+// WithMaxConcurrentStreams limits the number of in-flight requests per target host
+// across all the races of the Race object. It should match the MAX_CONCURRENT_STREAMS
+// advertised by HTTP/2 servers, otherwise attempts beyond the limit are silently
+// serialized by the transport and the outcome of the race is distorted
+func WithMaxConcurrentStreams(n int, policy StreamPolicy) Option {
+	return func(race *Race) {
+		race.maxStreams = n
+		race.streamPolicy = policy
+	}
+}
+
+// WithStreamQueue bounds the queue of the Queue policy, so bursts are smoothed out
+// without waiting forever: at most maxLength attempts wait for a free stream of a target,
+// in FIFO order, the others fail with ErrTargetSaturated, and an attempt that waits longer
+// than maxWait fails with ErrQueueTimeout. Zero means no limit
+func WithStreamQueue(maxLength int, maxWait time.Duration) Option {
+	return func(race *Race) {
+		race.queueLength = maxLength
+		race.queueWait = maxWait
+	}
+}
+
+// QueueLength returns the number of attempts waiting for a free stream of the target host
+func (race *Race) QueueLength(host string) int {
+	race.mu.Lock()
+	defer race.mu.Unlock()
+
+	if s, ok := race.streams[host]; ok {
+		return s.waiting
+	}
+	return 0
+}
+
+// streams holds the in-flight requests of a host
+type streams struct {
+	slots   *semaphore.Weighted
+	waiting int
+}
+
+// acquireStream reserves a stream on the given host
+// the returned function must be called to release it
+func (race *Race) acquireStream(ctx context.Context, host string) (func(), error) {
+	if race.maxStreams <= 0 {
+		return func() {}, nil
+	}
+
+	race.mu.Lock()
+	if race.streams == nil {
+		race.streams = make(map[string]*streams)
+	}
+	s, ok := race.streams[host]
+	if !ok {
+		s = &streams{slots: semaphore.NewWeighted(int64(race.maxStreams))}
+		race.streams[host] = s
+	}
+	race.mu.Unlock()
+
+	release := func() { s.slots.Release(1) }
+
+	if s.slots.TryAcquire(1) {
+		return release, nil
+	}
+	if race.streamPolicy == Spill {
+		return nil, ErrTargetSaturated
+	}
+
+	race.mu.Lock()
+	if race.queueLength > 0 && s.waiting >= race.queueLength {
+		race.mu.Unlock()
+		return nil, ErrTargetSaturated
+	}
+	s.waiting++
+	race.mu.Unlock()
+
+	defer func() {
+		race.mu.Lock()
+		s.waiting--
+		race.mu.Unlock()
+	}()
+
+	waitCtx := ctx
+	if race.queueWait > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, race.queueWait)
+		defer cancel()
+	}
+
+	// the waiters of a semaphore are served in FIFO order
+	if err := s.slots.Acquire(waitCtx, 1); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, ErrQueueTimeout
+	}
+	return release, nil
+}
+
+// WithMaxConcurrency limits the number of in-flight attempts of all the races of the Race,
+// attempts beyond the limit wait for a free slot, the earlier tiers and the primaries first.
+// The races nested in an attempt take the free slots or share its slot, so they never wait for their parents
+func WithMaxConcurrency(n int64) Option {
+	return func(race *Race) {
+		race.concurrency = newLimiter(n)
+	}
+}
+
+// acquireSlot reserves one of the in-flight attempts of the Race
+// the returned function must be called to release it
+func (race *Race) acquireSlot(ctx context.Context) (func(), error) {
+	if race.concurrency == nil {
+		return func() {}, nil
+	}
+	if release, nested, err := race.acquireNestedSlot(ctx); nested {
+		return release, err
+	}
+
+	if err := race.concurrency.Acquire(ctx, priorityOf(ctx)); err != nil {
+		return nil, err
+	}
+	return race.concurrency.Release, nil
+}
+
+// releaseOnClose keeps the stream reserved until the body of the response is closed,
+// because that's when the HTTP/2 stream is actually finished
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (body *releaseOnClose) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(body.release)
+	return err
+}
@@ -0,0 +1,184 @@
+package race
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+func TestMaxConcurrentStreams_Spill(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	r := New(WithMaxConcurrentStreams(1, Spill))
+
+	first, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.Between(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the body of the first response is still open so its stream is still in use
+	second, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Between(second)
+	multiError, ok := err.(*multierror.Error)
+	if !ok {
+		t.Fatal("Expected error of type *multierror.Error")
+	}
+	if !errors.Is(multiError.Errors[0], ErrTargetSaturated) {
+		t.Fatalf("Expected ErrTargetSaturated, got %v", multiError.Errors[0])
+	}
+
+	res.Body.Close()
+
+	res, err = r.Between(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
+
+func TestMaxConcurrentStreams_Queue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	r := NewWithClient(&http.Client{
+		Timeout: 200 * time.Millisecond,
+	}, WithMaxConcurrentStreams(1, Queue))
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// waits for the stream until the timeout
+	if _, err := r.Between(req); err == nil {
+		t.Fatal("Expected the queued request to time out")
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		res.Body.Close()
+	}()
+
+	res, err = r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
+
+func TestStreamQueue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(WithMaxConcurrentStreams(1, Queue), WithStreamQueue(1, 200*time.Millisecond))
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queued := make(chan error)
+	go func() {
+		_, err := r.Between(req)
+		queued <- err
+	}()
+
+	for r.QueueLength(u.Host) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the queue is full
+	_, err = r.Between(req)
+	if multiError, ok := err.(*multierror.Error); !ok || !errors.Is(multiError.Errors[0], ErrTargetSaturated) {
+		t.Fatalf("Expected ErrTargetSaturated, got %v", err)
+	}
+
+	err = <-queued
+	if multiError, ok := err.(*multierror.Error); !ok || !errors.Is(multiError.Errors[0], ErrQueueTimeout) {
+		t.Fatalf("Expected ErrQueueTimeout, got %v", err)
+	}
+	if r.QueueLength(u.Host) != 0 {
+		t.Fatal("Expected the queue to be empty")
+	}
+
+	res.Body.Close()
+
+	res, err = r.Between(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server1.Close()
+
+	server2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server2.Close()
+
+	r := NewWithClient(&http.Client{
+		Timeout: 100 * time.Millisecond,
+	}, WithMaxConcurrency(1))
+
+	req1, err := http.NewRequest("GET", server1.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req2, err := http.NewRequest("GET", server2.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := r.Between(req1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the limit is shared by all the targets
+	if _, err := r.Between(req2); Classify(err) != FailureTimeout {
+		t.Fatalf("Expected the attempt to wait for a free slot until the timeout, got %v", err)
+	}
+
+	res.Body.Close()
+
+	res, err = r.Between(req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
@@ -0,0 +1,288 @@
+package race
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Target is a mirror that a request can be sent to
+type Target struct {
+	// URL of the target, only its scheme and host are used
+	URL string
+	// Label names the target, e.g. its region, to select the targets of a TargetSet
+	Label string
+	// Weight orders the targets of a TargetSet, the highest first
+	Weight int
+	// Domain is the failure domain of the target, e.g. its zone or provider, see FailureDomains
+	Domain string
+	// Auth is applied to the requests sent to the target, it can be nil
+	Auth *Auth
+	// Client sends the requests to the target instead of the client of the Race, it can be nil
+	Client *http.Client
+	// TLS is the TLS configuration of the connections to the target, e.g. a client
+	// certificate. It's ignored if Client is set, and applied to a copy of the client of the Race
+	// when its transport is an *http.Transport
+	TLS *tls.Config
+	// MaxDecompressionRatio overrides the ratio of WithMaxDecompressionRatio for the target, e.g. for an
+	// untrusted mirror, and enforces it even if the Race has none. Zero keeps the ratio of the Race
+	MaxDecompressionRatio float64
+}
+
+// Auth holds the credentials of a target, all the non-empty fields are applied
+type Auth struct {
+	// Username and Password are sent with basic authentication
+	Username string
+	Password string
+	// Bearer is sent as a bearer token
+	Bearer string
+	// Header is added to the headers of the request, replacing the existing values
+	Header http.Header
+}
+
+// Clone returns a copy of the request sent to the target, it has its own body if the request has GetBody
+func (target Target) Clone(req *http.Request) (*http.Request, error) {
+	return target.clone(req, req.GetBody)
+}
+
+// clone returns a copy of the request sent to the target with a body returned by getBody
+func (target Target) clone(req *http.Request, getBody func() (io.ReadCloser, error)) (*http.Request, error) {
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := req.Clone(context.WithValue(req.Context(), targetKey{}, &target))
+	clone.URL.Scheme = u.Scheme
+	clone.URL.Host = u.Host
+	clone.Host = u.Host
+	target.Auth.apply(clone)
+	if err := withBody(clone, getBody); err != nil {
+		return nil, err
+	}
+
+	return clone, nil
+}
+
+func (auth *Auth) apply(req *http.Request) {
+	if auth == nil {
+		return
+	}
+
+	if auth.Username != "" || auth.Password != "" {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	if auth.Bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+auth.Bearer)
+	}
+	for name, values := range auth.Header {
+		req.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+}
+
+// BetweenTargets sends a copy of the request to every target simultaneously,
+// the first answer will be returned
+func (race *Race) BetweenTargets(req *http.Request, targets ...Target) (*http.Response, error) {
+	reqs, err := race.targetRequests(req, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	return race.Between(reqs...)
+}
+
+// targetRequests returns the copies of the request sent to the targets, each one with its own copy
+// of the body which is read in memory if the request has no GetBody
+func (race *Race) targetRequests(req *http.Request, targets []Target) ([]*http.Request, error) {
+	getBody, err := replayableBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs := make([]*http.Request, len(targets))
+	for i, target := range targets {
+		clone, err := target.clone(req, getBody)
+		if err != nil {
+			return nil, err
+		}
+		reqs[i] = clone
+	}
+	return reqs, nil
+}
+
+// BetweenTargets sends a copy of the request to every target simultaneously,
+// the first answer will be returned
+func BetweenTargets(req *http.Request, targets ...Target) (*http.Response, error) {
+	return New().BetweenTargets(req, targets...)
+}
+
+// BetweenHosts sends a copy of the request to every host simultaneously, only the scheme and the host
+// of the copies are replaced, e.g. "https://replica1.example" or "replica1.example:8080" which keeps the
+// scheme of the request. The first answer will be returned
+func (race *Race) BetweenHosts(req *http.Request, hosts ...string) (*http.Response, error) {
+	targets := make([]Target, len(hosts))
+	for i, host := range hosts {
+		if !strings.Contains(host, "://") {
+			host = req.URL.Scheme + "://" + host
+		}
+		targets[i] = Target{URL: host}
+	}
+
+	return race.BetweenTargets(req, targets...)
+}
+
+// BetweenHosts sends a copy of the request to every host simultaneously,
+// the first answer will be returned
+func BetweenHosts(req *http.Request, hosts ...string) (*http.Response, error) {
+	return New().BetweenHosts(req, hosts...)
+}
+
+// BetweenSet sends a copy of the request to the targets of the set, see TargetSet.Targets
+func (race *Race) BetweenSet(req *http.Request, set *TargetSet) (*http.Response, error) {
+	return race.BetweenTargets(req, set.Targets()...)
+}
+
+type targetKey struct{}
+
+// TargetOf returns the Target the request was copied for by Target.Clone or BetweenTargets,
+// so strategies can order the requests by the label, weight or domain of their targets
+func TargetOf(req *http.Request) (Target, bool) {
+	target, ok := req.Context().Value(targetKey{}).(*Target)
+	if !ok {
+		return Target{}, false
+	}
+	return *target, true
+}
+
+// withTarget returns the context carrying the target of the request, if it has one
+func withTarget(ctx context.Context, req *http.Request) context.Context {
+	if target, ok := req.Context().Value(targetKey{}).(*Target); ok {
+		return context.WithValue(ctx, targetKey{}, target)
+	}
+	return ctx
+}
+
+// clientFor returns the client sending the request, the one of its Attempt or of its target
+// if they have one, the client of the Race otherwise
+func (race *Race) clientFor(req *http.Request) *http.Client {
+	if attempt := attemptOf(req.Context()); attempt != nil && attempt.Client != nil {
+		return attempt.Client
+	}
+
+	target, ok := req.Context().Value(targetKey{}).(*Target)
+	switch {
+	case !ok || target.Client == nil && target.TLS == nil:
+		return race.client
+	case target.Client != nil:
+		return target.Client
+	}
+
+	race.mu.Lock()
+	defer race.mu.Unlock()
+
+	// the clients are cached by TLS configuration so their transports keep their idle connections
+	if client, ok := race.tlsClients[target.TLS]; ok {
+		return client
+	}
+
+	client := race.client
+	base, ok := client.Transport.(*http.Transport)
+	if client.Transport == nil {
+		base, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if ok {
+		transport := base.Clone()
+		transport.TLSClientConfig = target.TLS
+		copied := *client
+		copied.Transport = transport
+		client = &copied
+	}
+
+	if race.tlsClients == nil {
+		race.tlsClients = make(map[*tls.Config]*http.Client)
+	}
+	race.tlsClients[target.TLS] = client
+	return client
+}
+
+// TargetSet is a collection of targets and their health, it's safe for concurrent use
+type TargetSet struct {
+	mu        sync.Mutex
+	targets   []Target
+	unhealthy map[string]bool
+}
+
+// NewTargetSet returns a set of the targets, they are all healthy
+func NewTargetSet(targets ...Target) *TargetSet {
+	return &TargetSet{
+		targets:   append([]Target(nil), targets...),
+		unhealthy: make(map[string]bool),
+	}
+}
+
+// All returns all the targets of the set in the order they were given
+func (set *TargetSet) All() []Target {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	return append([]Target(nil), set.targets...)
+}
+
+// Targets returns the healthy targets ordered by weight, the highest first.
+// If none of them is healthy all the targets are returned, so the race still takes place
+func (set *TargetSet) Targets() []Target {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	var targets []Target
+	for _, target := range set.targets {
+		if !set.unhealthy[target.URL] {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		targets = append(targets, set.targets...)
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].Weight > targets[j].Weight
+	})
+	return targets
+}
+
+// Subset returns a new set of the targets with one of the labels, they keep their health
+func (set *TargetSet) Subset(labels ...string) *TargetSet {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	subset := NewTargetSet()
+	for _, target := range set.targets {
+		for _, label := range labels {
+			if target.Label == label {
+				subset.targets = append(subset.targets, target)
+				subset.unhealthy[target.URL] = set.unhealthy[target.URL]
+				break
+			}
+		}
+	}
+	return subset
+}
+
+// SetHealthy sets the health of the target with the URL
+func (set *TargetSet) SetHealthy(url string, healthy bool) {
+	set.mu.Lock()
+	set.unhealthy[url] = !healthy
+	set.mu.Unlock()
+}
+
+// Update sets the health of the targets from the statuses returned by CheckAll
+func (set *TargetSet) Update(statuses []Status) {
+	for _, status := range statuses {
+		set.SetHealthy(status.Target.URL, status.Healthy())
+	}
+}
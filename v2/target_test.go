@@ -0,0 +1,178 @@
+package race
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBetweenTargets_Auth(t *testing.T) {
+	basicServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("basic " + r.URL.Path + " " + r.Header.Get("X-Tenant")))
+	}))
+	defer basicServer.Close()
+
+	bearerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("bearer " + r.URL.Path))
+	}))
+	defer bearerServer.Close()
+
+	req, err := http.NewRequest("GET", "http://placeholder/file", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target Target
+		body   string
+	}{
+		{Target{URL: basicServer.URL, Auth: &Auth{Username: "user", Password: "pass", Header: http.Header{"X-Tenant": {"acme"}}}}, "basic /file acme"},
+		{Target{URL: bearerServer.URL, Auth: &Auth{Bearer: "token"}}, "bearer /file"},
+	}
+
+	for _, test := range tests {
+		res, err := BetweenTargets(req, test.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != test.body {
+			t.Fatalf("Expected %q, got %q", test.body, body)
+		}
+	}
+
+	if req.Header.Get("Authorization") != "" {
+		t.Fatal("Expected the original request not to be modified")
+	}
+}
+
+func TestBetweenHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method + " " + r.URL.RequestURI() + " " + r.Header.Get("X-Tenant")))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("DELETE", "http://placeholder/users/1?force=true", nil)
+	req.Header.Set("X-Tenant", "acme")
+
+	for _, host := range []string{server.URL, strings.TrimPrefix(server.URL, "http://")} {
+		res, err := BetweenHosts(req, unresolvableDomain, host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if string(body) != "DELETE /users/1?force=true acme" {
+			t.Fatalf("Expected the request to be copied to %s, got %q", host, body)
+		}
+	}
+}
+
+func TestTargetTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	r := New()
+	req, _ := http.NewRequest("GET", "https://placeholder/", nil)
+	if _, err := r.BetweenTargets(req, Target{URL: server.URL}); err == nil {
+		t.Fatal("Expected the certificate not to be trusted by default")
+	}
+
+	config := &tls.Config{RootCAs: pool}
+	for i := 0; i < 2; i++ {
+		res, err := r.BetweenTargets(req, Target{URL: server.URL}, Target{URL: server.URL, TLS: config})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	if len(r.tlsClients) != 1 {
+		t.Fatalf("Expected the client of the TLS configuration to be reused, got %d clients", len(r.tlsClients))
+	}
+
+	plain, _ := http.NewRequest("GET", server.URL, nil)
+	if _, err := r.Between(plain); err == nil {
+		t.Fatal("Expected the TLS configuration of the target not to be used by other races")
+	}
+
+	res, err := r.BetweenTargets(req, Target{URL: server.URL, Client: server.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
+
+func TestTargetOf(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://placeholder/", nil)
+	if _, ok := TargetOf(req); ok {
+		t.Fatal("Expected no target for a plain request")
+	}
+
+	clone, err := Target{URL: "http://replica1.example", Label: "eu", Domain: "zone-a"}.Clone(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, ok := TargetOf(clone)
+	if !ok || target.Label != "eu" || target.Domain != "zone-a" {
+		t.Fatalf("Expected the target of the copy, got %+v", target)
+	}
+}
+
+func TestTargetSet(t *testing.T) {
+	set := NewTargetSet(
+		Target{URL: "http://a", Label: "eu", Weight: 1},
+		Target{URL: "http://b", Label: "us", Weight: 3},
+		Target{URL: "http://c", Label: "eu", Weight: 2},
+	)
+
+	urls := func(targets []Target) string {
+		var s []string
+		for _, target := range targets {
+			s = append(s, strings.TrimPrefix(target.URL, "http://"))
+		}
+		return strings.Join(s, ",")
+	}
+
+	if got := urls(set.Targets()); got != "b,c,a" {
+		t.Fatalf("Expected the targets by weight, got %s", got)
+	}
+
+	set.Update([]Status{{Target: Target{URL: "http://b"}, Err: errors.New("down")}})
+	if got := urls(set.Targets()); got != "c,a" {
+		t.Fatalf("Expected the unhealthy target to be left out, got %s", got)
+	}
+	if got := urls(set.All()); got != "a,b,c" {
+		t.Fatalf("Expected all the targets, got %s", got)
+	}
+
+	eu := set.Subset("eu")
+	eu.SetHealthy("http://a", false)
+	eu.SetHealthy("http://c", false)
+	if got := urls(eu.Targets()); got != "c,a" {
+		t.Fatalf("Expected all the targets when none is healthy, got %s", got)
+	}
+	if got := urls(set.Targets()); got != "c,a" {
+		t.Fatalf("Expected the subset not to change the set, got %s", got)
+	}
+}
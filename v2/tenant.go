@@ -0,0 +1,113 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// ErrTenantLimited is the error of the hedges dropped because their tenant has too many in-flight hedges
+var ErrTenantLimited = errors.New("race: tenant limited")
+
+type tenantKey struct{}
+
+// ContextWithTenant returns a context whose races are accounted to the tenant, see WithTenantLimits
+func ContextWithTenant(parent context.Context, tenant string) context.Context {
+	return context.WithValue(parent, tenantKey{}, tenant)
+}
+
+// TenantLimits are the limits of every tenant of a Race, zero means no limit
+type TenantLimits struct {
+	// MaxConcurrency limits the in-flight attempts of a tenant,
+	// the attempts beyond the limit wait for one of them to finish
+	MaxConcurrency int64
+	// MaxHedges limits the in-flight hedges of a tenant, i.e. the attempts that aren't the primary of
+	// their race, the hedges beyond the limit are dropped and fail with ErrTenantLimited
+	MaxHedges int64
+}
+
+// WithTenantLimits applies the limits to each tenant of the races started with a context of ContextWithTenant,
+// so a noisy tenant of a shared Race waits for its own attempts and loses its hedges first instead of
+// starving the others. The races of the contexts without a tenant aren't limited
+func WithTenantLimits(limits TenantLimits) Option {
+	return func(race *Race) {
+		race.tenantLimits = &limits
+	}
+}
+
+type tenant struct {
+	slots  *semaphore.Weighted
+	hedges int64
+}
+
+// acquireTenant reserves an attempt of the tenant of the context, whose priority tells whether
+// it's a hedge. The returned function must be called to release it
+func (race *Race) acquireTenant(ctx context.Context) (func(), error) {
+	name, ok := ctx.Value(tenantKey{}).(string)
+	if race.tenantLimits == nil || !ok {
+		return func() {}, nil
+	}
+	t := race.tenant(name)
+	limits := race.tenantLimits
+
+	hedge := priorityOf(ctx).hedge && limits.MaxHedges > 0
+	if hedge && atomic.AddInt64(&t.hedges, 1) > limits.MaxHedges {
+		atomic.AddInt64(&t.hedges, -1)
+		return nil, ErrTenantLimited
+	}
+	releaseHedge := func() {
+		if hedge {
+			atomic.AddInt64(&t.hedges, -1)
+		}
+	}
+
+	if t.slots == nil {
+		return releaseHedge, nil
+	}
+	if err := t.slots.Acquire(ctx, 1); err != nil {
+		releaseHedge()
+		return nil, err
+	}
+	return func() {
+		t.slots.Release(1)
+		releaseHedge()
+	}, nil
+}
+
+// tenant returns the state of the tenant with the name
+func (race *Race) tenant(name string) *tenant {
+	race.mu.Lock()
+	defer race.mu.Unlock()
+
+	t, ok := race.tenants[name]
+	if !ok {
+		t = &tenant{}
+		if race.tenantLimits.MaxConcurrency > 0 {
+			t.slots = semaphore.NewWeighted(race.tenantLimits.MaxConcurrency)
+		}
+		if race.tenants == nil {
+			race.tenants = make(map[string]*tenant)
+		}
+		race.tenants[name] = t
+	}
+	return t
+}
+
+// doForTenant makes the attempt within the limits of its tenant, which is released when its body is closed
+func (race *Race) doForTenant(req *http.Request) (*http.Response, error) {
+	release, err := race.acquireTenant(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := race.doWithDeadline(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	res.Body = &releaseOnClose{ReadCloser: res.Body, release: release}
+	return res, nil
+}
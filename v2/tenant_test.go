@@ -0,0 +1,113 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTenantLimits(t *testing.T) {
+	r := New(WithTenantLimits(TenantLimits{MaxConcurrency: 2, MaxHedges: 1}))
+	defer r.Close()
+
+	noisy := ContextWithTenant(context.Background(), "noisy")
+	quiet := ContextWithTenant(context.Background(), "quiet")
+	hedge := func(ctx context.Context) context.Context {
+		return withPriority(ctx, priority{index: 1, hedge: true})
+	}
+
+	releaseHedge, err := r.acquireTenant(hedge(noisy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.acquireTenant(hedge(noisy)); !errors.Is(err, ErrTenantLimited) || Classify(err) != FailureSaturated {
+		t.Fatalf("Expected the second hedge to be limited, got %v", err)
+	}
+	release, err := r.acquireTenant(hedge(quiet))
+	if err != nil {
+		t.Fatalf("Expected the hedge of another tenant to be made, got %v", err)
+	}
+	release()
+
+	releasePrimary, err := r.acquireTenant(noisy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(noisy, 20*time.Millisecond)
+	defer cancel()
+	if _, err := r.acquireTenant(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the primary to wait for a slot of its tenant, got %v", err)
+	}
+	release, err = r.acquireTenant(quiet)
+	if err != nil {
+		t.Fatalf("Expected the primary of another tenant to be made, got %v", err)
+	}
+	release()
+
+	releasePrimary()
+	releaseHedge()
+	release, err = r.acquireTenant(hedge(noisy))
+	if err != nil {
+		t.Fatalf("Expected the released hedge to be available again, got %v", err)
+	}
+	release()
+}
+
+func TestTenantHedgesDropped(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	r := New(WithTenantLimits(TenantLimits{MaxHedges: 1}))
+	defer r.Close()
+
+	reqs := make([]*http.Request, 4)
+	for i := range reqs {
+		reqs[i], _ = http.NewRequest("GET", server.URL, nil)
+	}
+	res, err := r.BetweenContext(ContextWithTenant(context.Background(), "noisy"), reqs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if hits := atomic.LoadInt32(&hits); hits != 2 {
+		t.Fatalf("Expected the primary and a single hedge to be sent, got %d", hits)
+	}
+}
+
+func TestTenantPrimariesOfTier(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	r := New(WithTenantLimits(TenantLimits{MaxHedges: 1}))
+	defer r.Close()
+
+	primaries := make([]*http.Request, 3)
+	for i := range primaries {
+		primaries[i], _ = http.NewRequest("GET", server.URL, nil)
+	}
+	hedge, _ := http.NewRequest("GET", server.URL, nil)
+	res, err := r.CascadeContext(ContextWithTenant(context.Background(), "noisy"),
+		Tier{Requests: primaries, Timeout: time.Second},
+		Tier{Requests: []*http.Request{hedge}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if hits := atomic.LoadInt32(&hits); hits != 3 {
+		t.Fatalf("Expected the primaries of the first tier not to be limited as hedges, got %d requests", hits)
+	}
+}
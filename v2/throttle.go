@@ -0,0 +1,52 @@
+package race
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrHedgeThrottled is the error of the hedges that weren't made because the budget of WithHedgeBudget was
+// exhausted, it's an ErrNotAttempted so the failure of the race is classified by the attempts that were made
+var ErrHedgeThrottled = fmt.Errorf("%w: hedge budget exhausted", ErrNotAttempted)
+
+// hedgeBudget is a token bucket filled by the primaries and drained by the hedges
+type hedgeBudget struct {
+	ratio float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+// WithHedgeBudget limits the hedges to a ratio of the primaries, e.g. 0.1 for at most 10% extra requests,
+// so aggressive hedging can't multiply the load of the backends during an incident. Every primary adds
+// ratio to a bucket holding at most burst hedges, which starts full, and every hedge takes one from it.
+// When the bucket is empty the hedges fail with ErrHedgeThrottled and the races fall back to their primary
+func WithHedgeBudget(ratio float64, burst int) Option {
+	return func(race *Race) {
+		race.hedgeBudget = &hedgeBudget{ratio: ratio, burst: float64(burst), tokens: float64(burst)}
+	}
+}
+
+// spend records the start of a primary or a hedge, it returns ErrHedgeThrottled if it's a hedge over the budget
+func (b *hedgeBudget) spend(hedge bool) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !hedge {
+		b.tokens += b.ratio
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		return nil
+	}
+	if b.tokens < 1 {
+		return ErrHedgeThrottled
+	}
+	b.tokens--
+	return nil
+}
@@ -0,0 +1,74 @@
+package race
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedgeBudget(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer primary.Close()
+	var hedges int32
+	hedge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hedges, 1)
+	}))
+	defer hedge.Close()
+
+	r := New(WithHedgeBudget(0.5, 1))
+	defer r.Close()
+
+	for i := 0; i < 3; i++ {
+		req1, _ := http.NewRequest("GET", primary.URL, nil)
+		req2, _ := http.NewRequest("GET", hedge.URL, nil)
+		res, err := r.Between(req1, req2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	// the bucket starts full, then a hedge is earned every second race
+	if hedges := atomic.LoadInt32(&hedges); hedges != 2 {
+		t.Fatalf("Expected 2 hedges, got %d", hedges)
+	}
+
+	failing, _ := http.NewRequest("GET", unresolvableDomain, nil)
+	req, _ := http.NewRequest("GET", hedge.URL, nil)
+	_, err := r.Between(failing, req)
+	if !errors.Is(err, ErrHedgeThrottled) || Classify(err) != FailureDNS {
+		t.Fatalf("Expected the race to fall back to the primary, got %v", err)
+	}
+}
+
+func TestHedgeBudget_Primaries(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	r := New(WithHedgeBudget(0.5, 0))
+	defer r.Close()
+
+	primaries := make([]*http.Request, 3)
+	for i := range primaries {
+		primaries[i], _ = http.NewRequest("GET", server.URL, nil)
+	}
+	fallback, _ := http.NewRequest("GET", server.URL, nil)
+	res, err := r.PrimariesThenStart(primaries, time.Second, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	r.Close()
+
+	if hits := atomic.LoadInt32(&hits); hits != 3 {
+		t.Fatalf("Expected all the primaries to be made with an empty budget, got %d requests", hits)
+	}
+}
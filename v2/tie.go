@@ -0,0 +1,84 @@
+package race
+
+import "time"
+
+// defaultTieMargin is how close the responses of a near tie are without a tie window
+const defaultTieMargin = time.Millisecond
+
+// WithTieWindow waits up to the window after the first response of a race for the pending attempts
+// before it in the race order, e.g. the primary, and returns the first of them to succeed in
+// that order, so near ties are decided by preference rather than by the scheduling of goroutines.
+// The other responses are closed. The responses arriving within the window, or within a millisecond
+// without a tie window, are recorded as the Ties of the Record of the race
+func WithTieWindow(window time.Duration) Option {
+	return func(race *Race) {
+		race.tieWindow = window
+	}
+}
+
+// settle waits for the tie window after the first response while the attempts before it in the race
+// order are pending, i.e. haven't failed in errs, and returns the preferred response.
+// The failures it receives are added to errs and counted
+func (c *contest) settle(first outcome, errs []error) (outcome, int) {
+	pending := func(best outcome) bool {
+		for _, err := range errs[:best.index] {
+			if err == nil {
+				return true
+			}
+		}
+		return false
+	}
+	if c.race.tieWindow <= 0 || !pending(first) {
+		return first, 0
+	}
+
+	timer := time.NewTimer(c.race.tieWindow)
+	defer timer.Stop()
+
+	best := first
+	failures := 0
+	for pending(best) {
+		select {
+		case o := <-c.results:
+			if o.err != nil {
+				errs[o.index] = o.err
+				failures++
+				continue
+			}
+			if o.index < best.index {
+				best, o = o, best
+			}
+			c.recordLoser(o.res, nil)
+			o.res.Body.Close()
+		case <-timer.C:
+			return best, failures
+		}
+	}
+	return best, failures
+}
+
+// ties returns the indexes of the other attempts that succeeded within the tie margin of the winner
+func (race *Race) ties(attempts []AttemptRecord, winner int) []int {
+	margin := race.tieWindow
+	if margin <= 0 {
+		margin = defaultTieMargin
+	}
+
+	var won time.Duration
+	for _, attempt := range attempts {
+		if attempt.Index == winner {
+			won = attempt.Start + attempt.Elapsed
+		}
+	}
+
+	var ties []int
+	for _, attempt := range attempts {
+		if attempt.Index == winner || attempt.Err != "" {
+			continue
+		}
+		if diff := attempt.Start + attempt.Elapsed - won; diff <= margin && diff >= -margin {
+			ties = append(ties, attempt.Index)
+		}
+	}
+	return ties
+}
@@ -0,0 +1,88 @@
+package race
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTieWindow(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte("primary"))
+	}))
+	defer primary.Close()
+
+	hedge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hedge"))
+	}))
+	defer hedge.Close()
+
+	tests := []struct {
+		window time.Duration
+		winner string
+	}{
+		{0, "hedge"},
+		{200 * time.Millisecond, "primary"},
+	}
+
+	for _, test := range tests {
+		r := New(WithTieWindow(test.window), WithFlightRecorder(1))
+
+		req1, _ := http.NewRequest("GET", primary.URL, nil)
+		req2, _ := http.NewRequest("GET", hedge.URL, nil)
+		res, err := r.Between(req1, req2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if string(body) != test.winner {
+			t.Fatalf("Expected the %s to win with a window of %s, got %q", test.winner, test.window, body)
+		}
+
+		r.Close()
+		if test.window > 0 {
+			records := r.FlightRecords()
+			if len(records) != 1 || len(records[0].Ties) != 1 || records[0].Ties[0] != 1 {
+				t.Fatalf("Expected the hedge to be recorded as a tie, got %+v", records)
+			}
+		}
+	}
+}
+
+func TestTieWindow_FailedPrimary(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer primary.Close()
+
+	hedge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hedge"))
+	}))
+	defer hedge.Close()
+
+	r := New(WithTieWindow(5 * time.Second))
+	defer r.Close()
+
+	req1, _ := http.NewRequest("GET", primary.URL, nil)
+	req2, _ := http.NewRequest("GET", hedge.URL, nil)
+	start := time.Now()
+	result, err := r.BetweenResult(context.Background(), req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.Response.Body.Close()
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the window to end when the primary failed, took %s", elapsed)
+	}
+	if result.Index != 1 || result.Failed != 1 {
+		t.Fatalf("Expected the hedge to win after the primary failed, got index %d and %d failures", result.Index, result.Failed)
+	}
+}
@@ -0,0 +1,67 @@
+package race
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// ErrBodyTooLarge is the error of the attempts whose body is larger than the size given to WithTrailerCheck
+var ErrBodyTooLarge = errors.New("race: body too large")
+
+type trailerCheck struct {
+	maxBytes int64
+	check    func(trailer http.Header) error
+}
+
+// WithTrailerCheck reads the bodies of the attempts before they can win, so their trailers are
+// known, and calls check with the trailers, e.g. a checksum computed by the server or a status
+// telling that the generation of the content failed midway. The responses it returns an error for
+// fail and the race keeps waiting for the other attempts. The winner is returned with its body
+// buffered and its Trailer set. The bodies larger than maxBytes fail with ErrBodyTooLarge,
+// zero means no limit
+func WithTrailerCheck(maxBytes int64, check func(trailer http.Header) error) Option {
+	return func(race *Race) {
+		race.trailerCheck = &trailerCheck{maxBytes: maxBytes, check: check}
+	}
+}
+
+// TrailerEquals returns a check of WithTrailerCheck failing when the trailer doesn't have the value,
+// e.g. TrailerEquals("X-Generation-Status", "ok")
+func TrailerEquals(name, value string) func(trailer http.Header) error {
+	return func(trailer http.Header) error {
+		if got := trailer.Get(name); got != value {
+			return fmt.Errorf("race: trailer %s is %q instead of %q", name, got, value)
+		}
+		return nil
+	}
+}
+
+// checkTrailer buffers the body of the response and checks its trailers, the body is closed on error
+func (race *Race) checkTrailer(res *http.Response) error {
+	if race.trailerCheck == nil {
+		return nil
+	}
+
+	var r io.Reader = res.Body
+	if race.trailerCheck.maxBytes > 0 {
+		r = io.LimitReader(res.Body, race.trailerCheck.maxBytes+1)
+	}
+	body, err := ioutil.ReadAll(r)
+	res.Body.Close()
+	if err != nil {
+		return err
+	}
+	if race.trailerCheck.maxBytes > 0 && int64(len(body)) > race.trailerCheck.maxBytes {
+		return ErrBodyTooLarge
+	}
+	if err := race.trailerCheck.check(res.Trailer); err != nil {
+		return err
+	}
+
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return nil
+}
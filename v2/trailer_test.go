@@ -0,0 +1,60 @@
+package race
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTrailerCheck(t *testing.T) {
+	generator := func(status string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Trailer", "X-Generation-Status")
+			w.Write([]byte("generated by " + status))
+			w.Header().Set("X-Generation-Status", status)
+		}))
+	}
+	failedServer := generator("failed")
+	defer failedServer.Close()
+	okServer := generator("ok")
+	defer okServer.Close()
+
+	r := New(WithTrailerCheck(0, TrailerEquals("X-Generation-Status", "ok")))
+	defer r.Close()
+
+	req1, _ := http.NewRequest("GET", failedServer.URL, nil)
+	req2, _ := http.NewRequest("GET", okServer.URL, nil)
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, _ := ioutil.ReadAll(res.Body)
+	if string(body) != "generated by ok" || res.Trailer.Get("X-Generation-Status") != "ok" {
+		t.Fatalf("Expected the generation that succeeded with its trailers, got %q", body)
+	}
+
+	req, _ := http.NewRequest("GET", failedServer.URL, nil)
+	if _, err := r.Between(req); err == nil || !strings.Contains(err.Error(), "X-Generation-Status") {
+		t.Fatalf("Expected the race to fail the trailer check, got %v", err)
+	}
+}
+
+func TestTrailerCheckBodyTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("more than 8 bytes"))
+	}))
+	defer server.Close()
+
+	r := New(WithTrailerCheck(8, func(http.Header) error { return nil }))
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if _, err := r.Between(req); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("Expected ErrBodyTooLarge, got %v", err)
+	}
+}
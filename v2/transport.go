@@ -0,0 +1,31 @@
+package race
+
+import "net/http"
+
+// RacingTransport is an http.RoundTripper racing every request across the targets, so racing
+// can be dropped into the code accepting a custom transport, e.g. SDKs or oauth2 clients.
+// The scheme and the host of the request are replaced by the ones of each target and the first
+// answer is returned, use WithValidator(SuccessfulStatus) to only accept good responses.
+// The client of the Race must not use the RacingTransport itself
+type RacingTransport struct {
+	// Race makes the races, a new Race with the default client is used if it's nil
+	Race    *Race
+	Targets []Target
+}
+
+// RoundTrip races the request across the targets, the race is canceled with the context of the request
+func (t *RacingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	race := t.Race
+	if race == nil {
+		race = New()
+	}
+
+	reqs, err := race.targetRequests(req, t.Targets)
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return race.BetweenContext(req.Context(), reqs...)
+}
@@ -0,0 +1,43 @@
+package race
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRacingTransport(t *testing.T) {
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failingServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	r := New(WithValidator(SuccessfulStatus))
+	defer r.Close()
+	client := &http.Client{Transport: &RacingTransport{
+		Race:    r,
+		Targets: []Target{{URL: failingServer.URL}, {URL: server.URL}},
+	}}
+
+	res, err := client.Get("http://api.example/users/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK || string(body) != "/users/1" {
+		t.Fatalf("Expected the good response of the path, got %d %q", res.StatusCode, body)
+	}
+}
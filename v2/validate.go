@@ -0,0 +1,63 @@
+package race
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrUnsuccessful is the error of the attempts rejected by SuccessfulStatus
+var ErrUnsuccessful = errors.New("race: unsuccessful status")
+
+// Validator returns an error if the response of an attempt can't win the race,
+// it's called concurrently by the attempts
+type Validator func(res *http.Response) error
+
+// WithValidator appends the validator to the ones checking the responses of the attempts,
+// a response failing validation is closed and treated like an error, the race keeps
+// waiting for the other attempts. The validators are applied in the order of the options, they don't
+// apply to the 304 Not Modified answers of conditional requests, e.g. the revalidations of Warm
+func WithValidator(validator Validator) Option {
+	return func(race *Race) {
+		race.validators = append(race.validators, validator)
+	}
+}
+
+// SuccessfulStatus is a Validator rejecting the responses without a 2xx status
+func SuccessfulStatus(res *http.Response) error {
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("%w %s", ErrUnsuccessful, res.Status)
+	}
+	return nil
+}
+
+// isConditional reports whether the request is answered with 304 Not Modified if it's unchanged
+func isConditional(req *http.Request) bool {
+	return req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+}
+
+// validate returns the error of the response of the attempt, its body is closed on error
+func (race *Race) validate(req *http.Request, res *http.Response) error {
+	if err := race.checkRange(req, res); err != nil {
+		return err
+	}
+	if res.StatusCode == http.StatusNotModified && isConditional(req) {
+		return nil
+	}
+	for _, validator := range race.validators {
+		if err := validator(res); err != nil {
+			res.Body.Close()
+			return err
+		}
+	}
+	if attempt := attemptOf(req.Context()); attempt != nil && attempt.Validator != nil {
+		if err := attempt.Validator(res); err != nil {
+			res.Body.Close()
+			return err
+		}
+	}
+	if err := race.checkTrailer(res); err != nil {
+		return err
+	}
+	return race.checkDecompression(req, res)
+}
@@ -0,0 +1,60 @@
+package race
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidator(t *testing.T) {
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failingServer.Close()
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("X-Version", "2")
+	}))
+	defer slowServer.Close()
+
+	var mu sync.Mutex
+	var order []string
+	r := New(
+		WithValidator(func(res *http.Response) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, "status")
+			return SuccessfulStatus(res)
+		}),
+		WithValidator(func(res *http.Response) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, "version")
+			return nil
+		}),
+	)
+	defer r.Close()
+
+	req1, _ := http.NewRequest("GET", failingServer.URL, nil)
+	req2, _ := http.NewRequest("GET", slowServer.URL, nil)
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.Header.Get("X-Version") != "2" {
+		t.Fatal("Expected the valid response to win")
+	}
+	if len(order) != 3 || order[0] != "status" || order[1] != "status" || order[2] != "version" {
+		t.Fatalf("Expected the validators in the order of the options, got %v", order)
+	}
+
+	req, _ := http.NewRequest("GET", failingServer.URL, nil)
+	if _, err := r.Between(req); !errors.Is(err, ErrUnsuccessful) {
+		t.Fatalf("Expected the race to fail validation, got %v", err)
+	}
+}
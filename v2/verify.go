@@ -0,0 +1,90 @@
+package race
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+)
+
+// Mismatch is a winner whose body differs from the one another target returned
+// when it was fetched again, the bodies are truncated to the size given to WithVerification
+type Mismatch struct {
+	Winner       *http.Response
+	WinnerBody   []byte
+	Verifier     *http.Response
+	VerifierBody []byte
+}
+
+type verification struct {
+	rate     float64
+	maxBytes int64
+	fn       func(Mismatch)
+}
+
+// WithVerification fetches again the winners of the given fraction of the races (between 0 and 1)
+// from another target of the race, with a different host, and calls fn when the bodies are not
+// equivalent according to the Comparator of the Race: cheap continuous integrity monitoring of mirrors.
+// The winner is captured while the caller reads it, at most maxBytes of the bodies are compared, and
+// the verification is made in the background once the caller closes the body, within the budget of
+// WithLoserCompletion or captureTimeout. Only GET and HEAD requests are fetched again, the verifications
+// that fail or whose winner wasn't read to the end are dropped, and it's ignored in comparison mode
+func WithVerification(rate float64, maxBytes int64, fn func(Mismatch)) Option {
+	return func(race *Race) {
+		race.verification = &verification{rate: rate, maxBytes: maxBytes, fn: fn}
+	}
+}
+
+// sampleVerification chooses whether the winner is verified, and by which target
+func (c *contest) sampleVerification(winner *http.Request, res *http.Response) *http.Response {
+	v := c.race.verification
+	if v == nil || rand.Float64() >= v.rate {
+		return res
+	}
+	// fetching again must be safe
+	if winner.Method != http.MethodGet && winner.Method != http.MethodHead && winner.Method != "" {
+		return res
+	}
+
+	for _, target := range c.targets {
+		if target.URL.Host != winner.URL.Host {
+			c.verifier = target
+			c.verified = res
+			return c.captureWinner(res, v.maxBytes)
+		}
+	}
+	return res
+}
+
+// verify must be called once the race is over
+func (c *contest) verify() {
+	if c.verifier == nil {
+		return
+	}
+
+	// a partially read body would be a false mismatch
+	captured, ok := c.capturedWinner()
+	if !ok || !captured.complete {
+		return
+	}
+	winnerBody := captured.body
+
+	res, err := c.race.do(c.verifier.WithContext(withTarget(c.race.ctx, c.verifier)))
+	if err != nil {
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, c.race.verification.maxBytes))
+	res.Body.Close()
+	if err != nil {
+		return
+	}
+
+	if !c.race.equal(winnerBody, body) {
+		c.race.verification.fn(Mismatch{
+			Winner:       c.verified,
+			WinnerBody:   winnerBody,
+			Verifier:     res,
+			VerifierBody: body,
+		})
+	}
+}
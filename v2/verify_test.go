@@ -0,0 +1,118 @@
+package race
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerification(t *testing.T) {
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("stale"))
+	}))
+	defer fastServer.Close()
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte("fresh"))
+	}))
+	defer slowServer.Close()
+
+	mismatches := make(chan Mismatch, 1)
+	r := New(WithVerification(1, 1024, func(m Mismatch) {
+		mismatches <- m
+	}))
+	defer r.Close()
+
+	req1, _ := http.NewRequest("GET", fastServer.URL, nil)
+	req2, _ := http.NewRequest("GET", slowServer.URL, nil)
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	select {
+	case m := <-mismatches:
+		if string(m.WinnerBody) != "stale" || string(m.VerifierBody) != "fresh" {
+			t.Fatalf("Unexpected mismatch %q != %q", m.WinnerBody, m.VerifierBody)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the mismatch to be reported")
+	}
+}
+
+func TestVerificationAgrees(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("same"))
+	}))
+	defer server.Close()
+	mirror := httptest.NewServer(server.Config.Handler)
+	defer mirror.Close()
+
+	mismatches := make(chan Mismatch, 1)
+	r := New(WithVerification(1, 1024, func(m Mismatch) {
+		mismatches <- m
+	}))
+
+	req1, _ := http.NewRequest("GET", server.URL, nil)
+	req2, _ := http.NewRequest("GET", mirror.URL, nil)
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	// give the verification time before Close drops it
+	time.Sleep(100 * time.Millisecond)
+	r.Close()
+
+	select {
+	case m := <-mismatches:
+		t.Fatalf("Unexpected mismatch %q != %q", m.WinnerBody, m.VerifierBody)
+	default:
+	}
+}
+
+func TestVerification_Incomplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("stale"))
+	}))
+	defer server.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fresh"))
+	}))
+	defer mirror.Close()
+
+	mismatches := make(chan Mismatch, 2)
+	r := New(WithVerification(1, 1024, func(m Mismatch) {
+		mismatches <- m
+	}), WithLoserCompletion(50*time.Millisecond))
+	defer r.Close()
+
+	req1, _ := http.NewRequest("GET", server.URL, nil)
+	req2, _ := http.NewRequest("GET", mirror.URL, nil)
+
+	// closed without reading, then never closed
+	res, err := r.Between(req1, req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if _, err := r.Between(req1, req2); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case m := <-mismatches:
+		t.Fatalf("Expected the winners read partially not to be verified, got %q != %q", m.WinnerBody, m.VerifierBody)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
@@ -0,0 +1,137 @@
+package race
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// ErrNotWarmable is the error of Warm for races that can't be shared
+var ErrNotWarmable = errors.New("race: only the races of GET and HEAD requests without a body can be warmed")
+
+// warmed is the last result of a warmed race
+type warmed struct {
+	res     *http.Response
+	body    []byte
+	expires time.Time
+}
+
+// Warm races the requests in the background now and then every interval, until the context is done or
+// the Race is closed, so the identical races of Between and BetweenContext are answered from the last
+// result while it's younger than maxAge and the cost of racing moves off the critical path. The body
+// of the result is buffered and every caller gets its own copy. Races are identical when their requests
+// have the same method, URL and headers, only the races of GET and HEAD requests without a body are warmed.
+// Only 2xx results are kept, unless they have Cache-Control: no-store, and they're revalidated with their
+// ETag or Last-Modified, whatever the validators of the Race
+func (race *Race) Warm(ctx context.Context, interval, maxAge time.Duration, reqs ...*http.Request) error {
+	key, ok := race.raceKey(reqs)
+	if !ok {
+		return ErrNotWarmable
+	}
+
+	race.mu.Lock()
+	if race.warmers == nil {
+		race.warmers = make(map[string]int)
+	}
+	race.warmers[key]++
+	race.mu.Unlock()
+
+	race.background.Add(1)
+	go func() {
+		defer race.background.Done()
+		defer race.stopWarming(key)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			race.warm(ctx, key, maxAge, reqs)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			case <-race.ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// stopWarming drops the result of the race once none of the calls of Warm for it is running
+func (race *Race) stopWarming(key string) {
+	race.mu.Lock()
+	defer race.mu.Unlock()
+
+	race.warmers[key]--
+	if race.warmers[key] <= 0 {
+		delete(race.warmers, key)
+		delete(race.warmed, key)
+	}
+}
+
+// warm races the requests and keeps the successful result, a failed race keeps the previous one until
+// it expires. The race is conditional on the previous result, which is kept if it's not modified, and
+// the responses that must not be stored aren't kept
+func (race *Race) warm(ctx context.Context, key string, maxAge time.Duration, reqs []*http.Request) {
+	race.mu.Lock()
+	previous := race.warmed[key]
+	if previous != nil && time.Now().After(previous.expires) {
+		delete(race.warmed, key)
+		previous = nil
+	}
+	race.mu.Unlock()
+
+	res, err := race.between(ctx, revalidate(reqs, previous))
+	if err != nil {
+		return
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return
+	}
+
+	race.mu.Lock()
+	defer race.mu.Unlock()
+	if race.warmed == nil {
+		race.warmed = make(map[string]*warmed)
+	}
+	switch {
+	case res.StatusCode == http.StatusNotModified && previous != nil:
+		race.warmed[key] = &warmed{res: previous.res, body: previous.body, expires: time.Now().Add(maxAge)}
+	case hasDirective(res.Header, "no-store"):
+		delete(race.warmed, key)
+	case res.StatusCode >= 200 && res.StatusCode <= 299:
+		race.warmed[key] = &warmed{res: res, body: body, expires: time.Now().Add(maxAge)}
+	}
+}
+
+// warmResponse returns a copy of the warm result of the race of the requests, if there is one
+func (race *Race) warmResponse(reqs []*http.Request) (*http.Response, bool) {
+	race.mu.Lock()
+	defer race.mu.Unlock()
+	if len(race.warmed) == 0 {
+		return nil, false
+	}
+
+	key, ok := race.raceKey(reqs)
+	if !ok {
+		return nil, false
+	}
+	w, ok := race.warmed[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(w.expires) {
+		delete(race.warmed, key)
+		return nil, false
+	}
+
+	res := *w.res
+	res.Header = w.res.Header.Clone()
+	res.Body = ioutil.NopCloser(bytes.NewReader(w.body))
+	return &res, true
+}
@@ -0,0 +1,130 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarm(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		w.Write([]byte(strconv.Itoa(int(n))))
+	}))
+	defer server.Close()
+
+	r := New()
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if err := r.Warm(ctx, time.Hour, time.Hour, req); err != nil {
+		t.Fatal(err)
+	}
+	for atomic.LoadInt32(&hits) < 1 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	identical, _ := http.NewRequest("GET", server.URL, nil)
+	for i := 0; i < 2; i++ {
+		res, err := r.Between(identical)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if string(body) != "1" || atomic.LoadInt32(&hits) != 1 {
+			t.Fatalf("Expected the warm result, got %q after %d requests", body, atomic.LoadInt32(&hits))
+		}
+	}
+
+	cancel()
+	time.Sleep(30 * time.Millisecond)
+	stopped := atomic.LoadInt32(&hits)
+	res, err := r.Between(identical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if atomic.LoadInt32(&hits) != stopped+1 {
+		t.Fatal("Expected the race to be made once warming stopped")
+	}
+
+	post, _ := http.NewRequest("POST", server.URL, strings.NewReader("x"))
+	if err := r.Warm(context.Background(), time.Second, time.Second, post); !errors.Is(err, ErrNotWarmable) {
+		t.Fatalf("Expected POST not to be warmed, got %v", err)
+	}
+}
+
+func TestWarm_Expired(t *testing.T) {
+	var conditional int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			atomic.AddInt32(&conditional, 1)
+		}
+		w.Header().Set("ETag", `"v1"`)
+	}))
+	defer server.Close()
+
+	r := New()
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	key, _ := r.raceKey([]*http.Request{req})
+	r.warm(context.Background(), key, time.Millisecond, []*http.Request{req})
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := r.warmResponse([]*http.Request{req}); ok {
+		t.Fatal("Expected the expired result not to be used")
+	}
+	if len(r.warmed) != 0 {
+		t.Fatal("Expected the expired result to be evicted")
+	}
+
+	r.warm(context.Background(), key, time.Millisecond, []*http.Request{req})
+	time.Sleep(5 * time.Millisecond)
+	r.warm(context.Background(), key, time.Minute, []*http.Request{req})
+	if atomic.LoadInt32(&conditional) != 0 {
+		t.Fatal("Expected an expired result not to be revalidated")
+	}
+}
+
+func TestWarm_Concurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("warm"))
+	}))
+	defer server.Close()
+
+	r := New()
+	defer r.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	first, cancel := context.WithCancel(context.Background())
+	if err := r.Warm(first, time.Hour, time.Hour, req); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Warm(context.Background(), time.Hour, time.Hour, req); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, ok := r.warmResponse([]*http.Request{req}); ok {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := r.warmResponse([]*http.Request{req}); !ok {
+		t.Fatal("Expected the result to be kept while another call warms it")
+	}
+}